@@ -0,0 +1,175 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// The methods of this file implement the rotating calipers technique. The antipodal vertices
+// of the consecutive edges of a convex polygon advance monotonically around the polygon,
+// hence each query takes O(n) time after the initialization.
+//
+// Reference: G.T. Toussaint, Solving geometric problems with the rotating calipers,
+// Proc. IEEE MELECON'83, A10.02/1-4 (1983).
+//
+// Reference: H. Freeman, R. Shapira, Determining the minimum-area encasing rectangle
+// for an arbitrary closed curve, Comm. ACM, 18:409-413 (1975).
+//
+// See: http://dx.doi.org/10.1145/360881.360919
+
+// Diameter returns the farthest pair of vertices of c and the squared distance between them.
+// If c has no vertices, a run-time panic occurs.
+func (c ConvexPolygon2q) Diameter() (p, q Point2q, d2 Q) {
+	vs := c.vs
+	n := len(vs)
+	if n == 0 {
+		panic(ErrEmpty)
+	}
+	p, q = vs[0], vs[n-1]
+	d2 = p.Dist2(q)
+	if n < 3 {
+		return p, q, d2
+	}
+	at := func(i int) Point2q { return vs[i%n] }
+	try := func(a, b Point2q) {
+		if dd := a.Dist2(b); dd.Cmp(d2) > 0 {
+			p, q, d2 = a, b, dd
+		}
+	}
+	//
+	// For each edge (vi,vi+1) the vertex vj is the farthest from the line of the edge.
+	// If the edge (vj,vj+1) is parallel to it, then both vj and vj+1 are antipodal to vi and vi+1.
+	//
+	j := 1
+	for i := 0; i < n; i++ {
+		a, b := at(i), at(i+1)
+		for height2q(a, b, at(j+1)).Cmp(height2q(a, b, at(j))) > 0 {
+			j++
+		}
+		try(a, at(j))
+		try(b, at(j))
+		if height2q(a, b, at(j+1)).Cmp(height2q(a, b, at(j))) == 0 {
+			try(a, at(j+1))
+			try(b, at(j+1))
+		}
+	}
+	return p, q, d2
+}
+
+// DiameterErr is like Diameter, but it returns ErrEmpty if c has no vertices.
+func (c ConvexPolygon2q) DiameterErr() (p, q Point2q, d2 Q, err error) {
+	if len(c.vs) == 0 {
+		return Point2q{}, Point2q{}, qzer, ErrEmpty
+	}
+	p, q, d2 = c.Diameter()
+	return p, q, d2, nil
+}
+
+// Width returns the squared minimum width of c, i.e., the squared distance between the closest
+// pair of parallel lines enclosing c. One of these lines passes through the edge e of c,
+// and the other one passes through the vertex p of c. If c has fewer than 3 vertices,
+// then w2=0 and e is the segment spanned by c. If c has no vertices, a run-time panic occurs.
+func (c ConvexPolygon2q) Width() (w2 Q, e Segment2q, p Point2q) {
+	vs := c.vs
+	n := len(vs)
+	if n == 0 {
+		panic(ErrEmpty)
+	}
+	if n < 3 {
+		return qzer, Segment2q{vs[0], vs[n-1]}, vs[0]
+	}
+	at := func(i int) Point2q { return vs[i%n] }
+	j := 1
+	for i := 0; i < n; i++ {
+		a, b := at(i), at(i+1)
+		for height2q(a, b, at(j+1)).Cmp(height2q(a, b, at(j))) > 0 {
+			j++
+		}
+		//
+		// The squared distance from vj to the line of the edge is det²/|b-a|².
+		//
+		h := height2q(a, b, at(j))
+		h2 := h.Mul(h).Div(a.Dist2(b))
+		if i == 0 || h2.Cmp(w2) < 0 {
+			w2, e, p = h2, Segment2q{a, b}, at(j)
+		}
+	}
+	return w2, e, p
+}
+
+// WidthErr is like Width, but it returns ErrEmpty if c has no vertices.
+func (c ConvexPolygon2q) WidthErr() (w2 Q, e Segment2q, p Point2q, err error) {
+	if len(c.vs) == 0 {
+		return qzer, Segment2q{}, Point2q{}, ErrEmpty
+	}
+	w2, e, p = c.Width()
+	return w2, e, p, nil
+}
+
+// MinRectangle returns the minimum-area rectangle enclosing c and its area. The vertices of rect
+// are listed in counter-clockwise order, and one side of rect contains an edge of c. If c has
+// fewer than 3 vertices, then rect is c.Polygon() and area=0. If c has no vertices, a run-time
+// panic occurs.
+func (c ConvexPolygon2q) MinRectangle() (rect Polygon2q, area Q) {
+	vs := c.vs
+	n := len(vs)
+	if n == 0 {
+		panic(ErrEmpty)
+	}
+	if n < 3 {
+		return c.Polygon(), qzer
+	}
+	at := func(i int) Point2q { return vs[i%n] }
+	//
+	// For each edge (vi,vi+1) with the direction u: vj is the farthest from the line of the edge,
+	// vk maximizes u·v, and vl minimizes u·v.
+	//
+	u := vs[0].Vector(vs[1])
+	j, k, l := 1, c.extreme(u), c.extreme(u.Neg())
+	for i := 0; i < n; i++ {
+		a, b := at(i), at(i+1)
+		u = a.Vector(b)
+		for height2q(a, b, at(j+1)).Cmp(height2q(a, b, at(j))) > 0 {
+			j++
+		}
+		for u.Dot(at(k).Vector(at(k+1))).Sgn() > 0 {
+			k++
+		}
+		for u.Dot(at(l).Vector(at(l+1))).Sgn() < 0 {
+			l++
+		}
+		//
+		// The rectangle has the sides h=det(u,vj-vi)/|u| and w=u·(vk-vl)/|u|.
+		//
+		u2 := u.Abs2()
+		h := height2q(a, b, at(j))
+		w := u.Dot(at(l).Vector(at(k)))
+		ar := h.Mul(w).Div(u2)
+		if i == 0 || ar.Cmp(area) < 0 {
+			//
+			// The corners are a+(s·u+t·rot90(u))/|u|², where s=u·(v-a) and t=det(u,v-a).
+			//
+			tl, tk := u.Dot(a.Vector(at(l))), u.Dot(a.Vector(at(k)))
+			r := XYtoV(u.y.Neg(), u.x)
+			corner := func(s, t Q) Point2q {
+				return a.Add(u.Mul(s.Div(u2)).Add(r.Mul(t.Div(u2))))
+			}
+			rect = Polygon2q{[]Point2q{corner(tl, qzer), corner(tk, qzer), corner(tk, h), corner(tl, h)}}
+			area = ar
+		}
+	}
+	return rect, area
+}
+
+// MinRectangleErr is like MinRectangle, but it returns ErrEmpty if c has no vertices.
+func (c ConvexPolygon2q) MinRectangleErr() (rect Polygon2q, area Q, err error) {
+	if len(c.vs) == 0 {
+		return Polygon2q{}, qzer, ErrEmpty
+	}
+	rect, area = c.MinRectangle()
+	return rect, area, nil
+}
+
+// height2q returns det(b-a,c-a), i.e., the distance from c to the line through a and b
+// multiplied by |b-a|.
+func height2q(a, b, c Point2q) Q {
+	u, v := a.Vector(b), a.Vector(c)
+	return Det2x2(u.x, u.y, v.x, v.y)
+}
@@ -9,11 +9,17 @@ type Circle2q struct {
 }
 
 // CR2toCir returns a circle with a given center and radius squared.
+// If radius2 is negative, a run-time panic occurs.
 func CR2toCir(center Point2q, radius2 Q) Circle2q {
+	return must(CR2toCirErr(center, radius2))
+}
+
+// CR2toCirErr is like CR2toCir, but it returns ErrNegativeRadius if radius2 is negative.
+func CR2toCirErr(center Point2q, radius2 Q) (Circle2q, error) {
 	if radius2.Sgn() < 0 {
-		panic("negative radius2")
+		return Circle2q{}, ErrNegativeRadius
 	}
-	return Circle2q{center, radius2}
+	return Circle2q{center, radius2}, nil
 }
 
 // PPtoCir returns a circle having the segment [a,b] as its diameter.
@@ -23,20 +29,26 @@ func PPtoCir(a, b Point2q) Circle2q {
 	return Circle2q{cen, rsq}
 }
 
-// PPPtoCir returns a circle passing through three given points.
+// PPPtoCir returns a circle passing through three given points. If the points are collinear
+// and distinct, a run-time panic occurs.
 func PPPtoCir(a, b, c Point2q) Circle2q {
+	return must(PPPtoCirErr(a, b, c))
+}
+
+// PPPtoCirErr is like PPPtoCir, but it returns ErrCollinear if (a,b,c) are collinear and distinct.
+func PPPtoCirErr(a, b, c Point2q) (Circle2q, error) {
 	// Test if (a,b,c) are collinear.
 	if a.Orientation(b, c) == 0 {
 		if a.CmpXY(b) == 0 {
-			return PPtoCir(b, c)
+			return PPtoCir(b, c), nil
 		}
 		if b.CmpXY(c) == 0 {
-			return PPtoCir(c, a)
+			return PPtoCir(c, a), nil
 		}
 		if c.CmpXY(a) == 0 {
-			return PPtoCir(a, b)
+			return PPtoCir(a, b), nil
 		}
-		panic("collinear points")
+		return Circle2q{}, ErrCollinear
 	}
 	//
 	// tC2 returns the circumcenter of translated points.
@@ -61,7 +73,7 @@ func PPPtoCir(a, b, c Point2q) Circle2q {
 	//
 	cen := XYtoP(x, y)
 	rsq := cen.Dist2(a)
-	return Circle2q{cen, rsq}
+	return Circle2q{cen, rsq}, nil
 }
 
 // Center returns the center of c.
@@ -80,6 +92,9 @@ func (c Circle2q) Radius2() Q {
 //	 0 if a is on c
 //	+1 if a is inside c
 func (c Circle2q) Side(a Point2q) int {
+	if sgn, ok := side2f(c, a); ok {
+		return sgn
+	}
 	return c.rsq.Cmp(c.cen.Dist2(a))
 }
 
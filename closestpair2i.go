@@ -0,0 +1,26 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// ClosestPair2i computes the closest pair of points of a collection of points with integer coordinates
+// in the plane and the squared distance between them. It implements the divide and conquer algorithm;
+// the distances are computed in integer arithmetic. The function modifies the input ps by reordering it.
+// The results are the same as those of ClosestPair2q applied to the points converted by ToQ.
+// If ps has fewer than two points, a run-time panic occurs.
+//
+// Reference: M.I. Shamos, D. Hoey, Closest-point problems, Proc. 16th Annual Symposium on
+// Foundations of Computer Science, pp 151-162 (1975).
+//
+// See: http://dx.doi.org/10.1109/SFCS.1975.8
+func ClosestPair2i(ps []Point2i) (a, b Point2i, d2 Q) {
+	return closestpair2(ps)
+}
+
+// ClosestPair2iErr is like ClosestPair2i, but it returns ErrTooFew if ps has fewer than two points.
+func ClosestPair2iErr(ps []Point2i) (a, b Point2i, d2 Q, err error) {
+	if len(ps) < 2 {
+		return Point2i{}, Point2i{}, qzer, ErrTooFew
+	}
+	a, b, d2 = ClosestPair2i(ps)
+	return a, b, d2, nil
+}
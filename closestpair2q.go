@@ -0,0 +1,204 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// ClosestPair2q computes the closest pair of points of a collection of points in the plane and
+// the squared distance between them. It implements the divide and conquer algorithm. The points
+// are sorted in (x,y)-order first, so that duplicate points are found explicitly: if ps contains
+// duplicate points, then a pair of them is returned with d2=0. The function modifies the input ps
+// by reordering it. If ps has fewer than two points, a run-time panic occurs.
+//
+// Reference: M.I. Shamos, D. Hoey, Closest-point problems, Proc. 16th Annual Symposium on
+// Foundations of Computer Science, pp 151-162 (1975).
+//
+// See: http://dx.doi.org/10.1109/SFCS.1975.8
+func ClosestPair2q(ps []Point2q) (a, b Point2q, d2 Q) {
+	return closestpair2(ps)
+}
+
+// ClosestPair2qErr is like ClosestPair2q, but it returns ErrTooFew if ps has fewer than two points.
+func ClosestPair2qErr(ps []Point2q) (a, b Point2q, d2 Q, err error) {
+	if len(ps) < 2 {
+		return Point2q{}, Point2q{}, qzer, ErrTooFew
+	}
+	a, b, d2 = ClosestPair2q(ps)
+	return a, b, d2, nil
+}
+
+// dist2 is the kernel of the closest pair algorithm: the points with exact squared distances.
+type dist2[P any] interface {
+	CmpXY(b P) int
+	CmpYX(b P) int
+	Dist2(b P) Q
+	// dist2x returns the squared difference of the x-coordinates.
+	dist2x(b P) Q
+	// dist2y returns the squared difference of the y-coordinates.
+	dist2y(b P) Q
+}
+
+// closestpair2 computes the closest pair of points using the kernel P, see ClosestPair2q.
+func closestpair2[P dist2[P]](ps []P) (a, b P, d2 Q) {
+	n := len(ps)
+	if n < 2 {
+		panic(ErrTooFew)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].CmpXY(ps[j]) < 0 })
+	for i := 1; i < n; i++ {
+		if ps[i-1].CmpXY(ps[i]) == 0 {
+			return ps[i-1], ps[i], qzer
+		}
+	}
+	a, b, d2 = ps[0], ps[1], ps[0].Dist2(ps[1])
+	try := func(p, q P) {
+		if dd := p.Dist2(q); dd.Cmp(d2) < 0 {
+			a, b, d2 = p, q, dd
+		}
+	}
+	//
+	// rec(lo,hi) finds the closest pair in ps[lo:hi] sorted in (x,y)-order,
+	// and sorts ps[lo:hi] in (y,x)-order.
+	//
+	buf := make([]P, n)
+	var rec func(lo, hi int)
+	rec = func(lo, hi int) {
+		if hi-lo <= 3 {
+			for i := lo; i < hi; i++ {
+				for j := i + 1; j < hi; j++ {
+					try(ps[i], ps[j])
+				}
+			}
+			s := ps[lo:hi]
+			sort.Slice(s, func(i, j int) bool { return s[i].CmpYX(s[j]) < 0 })
+			return
+		}
+		mid := (lo + hi) / 2
+		m := ps[mid]
+		rec(lo, mid)
+		rec(mid, hi)
+		//
+		// Merge the halves in (y,x)-order.
+		//
+		i, j, k := lo, mid, lo
+		for i < mid || j < hi {
+			if j == hi || (i < mid && ps[i].CmpYX(ps[j]) <= 0) {
+				buf[k] = ps[i]
+				i++
+			} else {
+				buf[k] = ps[j]
+				j++
+			}
+			k++
+		}
+		copy(ps[lo:hi], buf[lo:hi])
+		//
+		// Check the points of the strip |px-x|<δ in (y,x)-order;
+		// each of them has O(1) neighbors closer than δ in y.
+		//
+		strip := buf[lo:lo]
+		for _, p := range ps[lo:hi] {
+			if p.dist2x(m).Cmp(d2) < 0 {
+				strip = append(strip, p)
+			}
+		}
+		for i := range strip {
+			for j := i + 1; j < len(strip); j++ {
+				if strip[j].dist2y(strip[i]).Cmp(d2) >= 0 {
+					break
+				}
+				try(strip[i], strip[j])
+			}
+		}
+	}
+	rec(0, n)
+	return a, b, d2
+}
+
+// ParClosestPair2q computes the closest pair of points of a collection of points in the plane and
+// the squared distance between them. The results are the same as those of ClosestPair2q, except that
+// another pair may be returned if several pairs have the minimum distance.
+// The function modifies the input ps by reordering it. If ps has fewer than two points, a run-time panic occurs.
+// If ncpu > 0 then computations run in parallel using ncpu goroutines;
+// otherwise computations run in parallel using runtime.NumCPU() goroutines.
+func ParClosestPair2q(ncpu int, ps []Point2q) (a, b Point2q, d2 Q) {
+	if ncpu <= 0 {
+		ncpu = runtime.NumCPU()
+	}
+	n := len(ps)
+	//
+	// No need to parallelize.
+	//
+	if n < 2*ncpu {
+		return ClosestPair2q(ps)
+	}
+	sort.Sort(p2qs(ps))
+	for i := 1; i < n; i++ {
+		if ps[i-1].CmpXY(ps[i]) == 0 {
+			return ps[i-1], ps[i], qzer
+		}
+	}
+	//
+	// The slabs ps[first:limit] are separated by the vertical lines x=xs[cpu].
+	//
+	xs := make([]Q, ncpu)
+	for cpu := range xs {
+		xs[cpu] = ps[cpu*n/ncpu].x
+	}
+	//
+	// Use mu to synchronize updating the closest pair.
+	//
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	none := true
+	try := func(p, q Point2q, dd Q) {
+		mu.Lock()
+		if none || dd.Cmp(d2) < 0 {
+			a, b, d2, none = p, q, dd, false
+		}
+		mu.Unlock()
+	}
+	//
+	// Parallel loop.
+	//
+	for cpu := 0; cpu < ncpu; cpu++ {
+		wg.Add(1)
+		first, limit := cpu*n/ncpu, (cpu+1)*n/ncpu
+		go func() {
+			defer wg.Done()
+			try(ClosestPair2q(ps[first:limit]))
+		}()
+	}
+	//
+	// Wait for all goroutines to finish.
+	//
+	wg.Wait()
+	//
+	// A closer pair crossing a separating line lies in the strip of width 2δ around it.
+	//
+	for _, x := range xs[1:] {
+		strip := make([]Point2q, 0)
+		for _, p := range ps {
+			if dx := p.x.Sub(x); dx.Mul(dx).Cmp(d2) < 0 {
+				strip = append(strip, p)
+			}
+		}
+		if len(strip) > 1 {
+			try(ClosestPair2q(strip))
+		}
+	}
+	return a, b, d2
+}
+
+// ParClosestPair2qErr is like ParClosestPair2q, but it returns ErrTooFew if ps has fewer than two points.
+func ParClosestPair2qErr(ncpu int, ps []Point2q) (a, b Point2q, d2 Q, err error) {
+	if len(ps) < 2 {
+		return Point2q{}, Point2q{}, qzer, ErrTooFew
+	}
+	a, b, d2 = ParClosestPair2q(ncpu, ps)
+	return a, b, d2, nil
+}
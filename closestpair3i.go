@@ -0,0 +1,30 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// ClosestPair3i computes the closest pair of points of a collection of points with integer coordinates
+// in the 3-dimensional space and the squared distance between them. It implements the randomized
+// incremental algorithm with a grid of cubic cells; the distances are computed in integer arithmetic.
+// The results are the same as those of ClosestPair3q applied to the points converted by ToQ, except that
+// another pair may be returned if several pairs have the minimum distance. The function modifies the input
+// ps by reordering it. If ps has fewer than two points, a run-time panic occurs.
+//
+// Reference: M. Golin, R. Raman, C. Schwarz, M. Smid, Simple randomized algorithms for closest pair
+// problems, Nordic J. Comput., 2:3-27 (1995).
+//
+// Reference: S. Khuller, Y. Matias, A simple randomized sieve algorithm for the closest-pair problem,
+// Inform. and Comput., 118:34-37 (1995).
+//
+// See: http://dx.doi.org/10.1006/inco.1995.1049
+func ClosestPair3i(ps []Point3i) (a, b Point3i, d2 Q) {
+	return closestpair3(ps)
+}
+
+// ClosestPair3iErr is like ClosestPair3i, but it returns ErrTooFew if ps has fewer than two points.
+func ClosestPair3iErr(ps []Point3i) (a, b Point3i, d2 Q, err error) {
+	if len(ps) < 2 {
+		return Point3i{}, Point3i{}, qzer, ErrTooFew
+	}
+	a, b, d2 = ClosestPair3i(ps)
+	return a, b, d2, nil
+}
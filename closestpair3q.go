@@ -0,0 +1,305 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// ClosestPair3q computes the closest pair of points of a collection of points in the 3-dimensional
+// space and the squared distance between them. It implements the randomized incremental algorithm
+// with a grid of cubic cells. The points are sorted in (x,y,z)-order first, so that duplicate points
+// are found explicitly: if ps contains duplicate points, then a pair of them is returned with d2=0.
+// The function modifies the input ps by reordering it. If ps has fewer than two points, a run-time
+// panic occurs.
+//
+// Reference: M. Golin, R. Raman, C. Schwarz, M. Smid, Simple randomized algorithms for closest pair
+// problems, Nordic J. Comput., 2:3-27 (1995).
+//
+// Reference: S. Khuller, Y. Matias, A simple randomized sieve algorithm for the closest-pair problem,
+// Inform. and Comput., 118:34-37 (1995).
+//
+// See: http://dx.doi.org/10.1006/inco.1995.1049
+func ClosestPair3q(ps []Point3q) (a, b Point3q, d2 Q) {
+	return closestpair3(ps)
+}
+
+// ClosestPair3qErr is like ClosestPair3q, but it returns ErrTooFew if ps has fewer than two points.
+func ClosestPair3qErr(ps []Point3q) (a, b Point3q, d2 Q, err error) {
+	if len(ps) < 2 {
+		return Point3q{}, Point3q{}, qzer, ErrTooFew
+	}
+	a, b, d2 = ClosestPair3q(ps)
+	return a, b, d2, nil
+}
+
+// cell3 is the kernel of the closest pair algorithm in the space: the points with exact squared
+// distances and the cells of a grid.
+type cell3[P any] interface {
+	CmpXYZ(b P) int
+	Dist2(b P) Q
+	// cell returns the cell of side 2^e containing the point.
+	cell(e int) [3]*big.Int
+}
+
+// closestpair3 computes the closest pair of points using the kernel P, see ClosestPair3q.
+func closestpair3[P cell3[P]](ps []P) (a, b P, d2 Q) {
+	n := len(ps)
+	if n < 2 {
+		panic(ErrTooFew)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].CmpXYZ(ps[j]) < 0 })
+	for i := 1; i < n; i++ {
+		if ps[i-1].CmpXYZ(ps[i]) == 0 {
+			return ps[i-1], ps[i], qzer
+		}
+	}
+	for k := n - 1; k >= 0; k-- {
+		i := rand.Intn(k + 1)
+		ps[k], ps[i] = ps[i], ps[k]
+	}
+	//
+	// The grid has the cells of side s=2^e, δ≤s<2δ, where δ is the current minimum distance.
+	// Any two points closer than δ lie in adjacent cells, and each cell holds O(1) points.
+	//
+	a, b, d2 = ps[0], ps[1], ps[0].Dist2(ps[1])
+	var e int
+	var grid grid3q
+	build := func(m int) {
+		e = log4q(d2)
+		grid = grid3q{make(map[[3]int64][]int), make(map[[3]string][]int)}
+		for i := 0; i < m; i++ {
+			grid.add(ps[i].cell(e), i)
+		}
+	}
+	build(2)
+	for i := 2; i < n; i++ {
+		p := ps[i]
+		c := p.cell(e)
+		//
+		// Scan the 27 cells around p.
+		//
+		found := false
+		grid.near(c, func(j int) {
+			if dd := p.Dist2(ps[j]); dd.Cmp(d2) < 0 {
+				a, b, d2, found = ps[j], p, dd, true
+			}
+		})
+		//
+		// Rebuild the grid if the cells are too large for the new minimum distance.
+		//
+		if found && log4q(d2) != e {
+			build(i + 1)
+		} else {
+			grid.add(c, i)
+		}
+	}
+	return a, b, d2
+}
+
+// log4q returns the integer e such that 4^(e-1) < x ≤ 4^e, x > 0.
+func log4q(x Q) int {
+	rx := r(x)
+	e := (rx.Num().BitLen() - rx.Denom().BitLen()) / 2
+	for x.Cmp(pow4q(e)) > 0 {
+		e++
+	}
+	for x.Cmp(pow4q(e-1)) <= 0 {
+		e--
+	}
+	return e
+}
+
+// pow4q returns 4^e.
+func pow4q(e int) Q {
+	if e < 0 {
+		return pow4q(-e).Inv()
+	}
+	return rtoq(new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(2*e))))
+}
+
+// cell returns the cell of side 2^e containing a, i.e., the integer parts of the coordinates
+// of a divided by 2^e.
+func (a Point3q) cell(e int) [3]*big.Int {
+	floor := func(x Q) *big.Int {
+		rx := r(x)
+		num, den := new(big.Int).Set(rx.Num()), new(big.Int).Set(rx.Denom())
+		if e < 0 {
+			num.Lsh(num, uint(-e))
+		} else {
+			den.Lsh(den, uint(e))
+		}
+		return num.Div(num, den)
+	}
+	return [3]*big.Int{floor(a.x), floor(a.y), floor(a.z)}
+}
+
+// grid3q maps the cells to the indices of the points in them. A cell is keyed by its coordinates
+// in small if they fit in int64, which is the common case, and by their decimal strings in large otherwise.
+type grid3q struct {
+	small map[[3]int64][]int
+	large map[[3]string][]int
+}
+
+// add adds the index i to the cell c.
+func (g grid3q) add(c [3]*big.Int, i int) {
+	if k, ok := small3q(c); ok {
+		g.small[k] = append(g.small[k], i)
+	} else {
+		k := large3q(c)
+		g.large[k] = append(g.large[k], i)
+	}
+}
+
+// near calls f for the indices in the cell c and in its 26 neighbors.
+func (g grid3q) near(c [3]*big.Int, f func(j int)) {
+	//
+	// The neighbors of a cell inside the int64 range are keyed by their coordinates.
+	//
+	inner := true
+	for _, x := range c {
+		inner = inner && x.IsInt64() && x.Int64() != math.MinInt64 && x.Int64() != math.MaxInt64
+	}
+	if inner {
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for dz := int64(-1); dz <= 1; dz++ {
+					for _, j := range g.small[[3]int64{c[0].Int64() + dx, c[1].Int64() + dy, c[2].Int64() + dz}] {
+						f(j)
+					}
+				}
+			}
+		}
+		return
+	}
+	var d [3]*big.Int
+	for i := range d {
+		d[i] = new(big.Int)
+	}
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for dz := int64(-1); dz <= 1; dz++ {
+				d[0].Add(c[0], big.NewInt(dx))
+				d[1].Add(c[1], big.NewInt(dy))
+				d[2].Add(c[2], big.NewInt(dz))
+				var js []int
+				if k, ok := small3q(d); ok {
+					js = g.small[k]
+				} else {
+					js = g.large[large3q(d)]
+				}
+				for _, j := range js {
+					f(j)
+				}
+			}
+		}
+	}
+}
+
+// small3q returns the coordinates of the cell c as int64 and true if they fit in int64.
+func small3q(c [3]*big.Int) (k [3]int64, ok bool) {
+	for i, x := range c {
+		if !x.IsInt64() {
+			return k, false
+		}
+		k[i] = x.Int64()
+	}
+	return k, true
+}
+
+// large3q returns the decimal strings of the coordinates of the cell c.
+func large3q(c [3]*big.Int) (k [3]string) {
+	for i, x := range c {
+		k[i] = x.String()
+	}
+	return k
+}
+
+// ParClosestPair3q computes the closest pair of points of a collection of points in the 3-dimensional
+// space and the squared distance between them. The results are the same as those of ClosestPair3q,
+// except that another pair may be returned if several pairs have the minimum distance.
+// The function modifies the input ps by reordering it. If ps has fewer than two points, a run-time panic occurs.
+// If ncpu > 0 then computations run in parallel using ncpu goroutines;
+// otherwise computations run in parallel using runtime.NumCPU() goroutines.
+func ParClosestPair3q(ncpu int, ps []Point3q) (a, b Point3q, d2 Q) {
+	if ncpu <= 0 {
+		ncpu = runtime.NumCPU()
+	}
+	n := len(ps)
+	//
+	// No need to parallelize.
+	//
+	if n < 2*ncpu {
+		return ClosestPair3q(ps)
+	}
+	sort.Sort(p3qs(ps))
+	for i := 1; i < n; i++ {
+		if ps[i-1].CmpXYZ(ps[i]) == 0 {
+			return ps[i-1], ps[i], qzer
+		}
+	}
+	//
+	// The slabs ps[first:limit] are separated by the planes x=xs[cpu].
+	//
+	xs := make([]Q, ncpu)
+	for cpu := range xs {
+		xs[cpu] = ps[cpu*n/ncpu].x
+	}
+	//
+	// Use mu to synchronize updating the closest pair.
+	//
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	none := true
+	try := func(p, q Point3q, dd Q) {
+		mu.Lock()
+		if none || dd.Cmp(d2) < 0 {
+			a, b, d2, none = p, q, dd, false
+		}
+		mu.Unlock()
+	}
+	//
+	// Parallel loop.
+	//
+	for cpu := 0; cpu < ncpu; cpu++ {
+		wg.Add(1)
+		first, limit := cpu*n/ncpu, (cpu+1)*n/ncpu
+		go func() {
+			defer wg.Done()
+			try(ClosestPair3q(ps[first:limit]))
+		}()
+	}
+	//
+	// Wait for all goroutines to finish.
+	//
+	wg.Wait()
+	//
+	// A closer pair crossing a separating plane lies in the slab of width 2δ around it.
+	//
+	for _, x := range xs[1:] {
+		slab := make([]Point3q, 0)
+		for _, p := range ps {
+			if dx := p.x.Sub(x); dx.Mul(dx).Cmp(d2) < 0 {
+				slab = append(slab, p)
+			}
+		}
+		if len(slab) > 1 {
+			try(ClosestPair3q(slab))
+		}
+	}
+	return a, b, d2
+}
+
+// ParClosestPair3qErr is like ParClosestPair3q, but it returns ErrTooFew if ps has fewer than two points.
+func ParClosestPair3qErr(ncpu int, ps []Point3q) (a, b Point3q, d2 Q, err error) {
+	if len(ps) < 2 {
+		return Point3q{}, Point3q{}, qzer, ErrTooFew
+	}
+	a, b, d2 = ParClosestPair3q(ncpu, ps)
+	return a, b, d2, nil
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2015 Leonid Kneller
+
+// Command pq runs the exact geometric algorithms of the package pq on points in the plane.
+//
+// Usage:
+//
+//	pq command [flags] [file ...]
+//
+// The commands are:
+//
+//	hull       the convex hull, see ConvHull2q and ParConvHull2q
+//	mincircle  the smallest enclosing circle, see MinCircle2q and ParMinCircle2q
+//	closest    the closest pair of points, see ClosestPair2q and ParClosestPair2q
+//
+// The points are read from the files, or from the standard input if there are none.
+// The input formats (-in) are:
+//
+//	auto  each line is a WKT geometry or a point as in csv or ws (default)
+//	csv   a point "x,y" per line; a first line without numbers is skipped as a header
+//	ws    a point "x y" per line
+//	wkt   a WKT POINT, MULTIPOINT, LINESTRING or POLYGON per line
+//
+// The coordinates are read exactly in any form accepted by pq.ParseQ, e.g. 12.375, 1e-3 or 1/3.
+// Empty lines and the lines starting with '#' are ignored.
+//
+// The output formats (-out) are:
+//
+//	text     exact coordinates, see below (default)
+//	wkt      OGC Well-Known Text
+//	geojson  GeoJSON (RFC 7946)
+//	json     exact coordinates as JSON strings
+//	svg      an SVG picture
+//
+// The text output of hull is the list of the hull vertices in counter-clockwise order,
+// one "x y" per line; the text output of mincircle is "x y r2", the center and the radius
+// squared; the text output of closest is "x1 y1", "x2 y2" and "d2", the squared distance,
+// on three lines. The exact coordinates are written by pq.FormatQ.
+//
+// The flag -par N runs the algorithms in parallel using N goroutines, or runtime.NumCPU()
+// goroutines if N is 0. By default the algorithms run sequentially.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/reconditematter/pq"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	in := fs.String("in", "auto", "input format: auto, csv, ws or wkt")
+	out := fs.String("out", "text", "output format: text, wkt, geojson, json or svg")
+	par := fs.Int("par", -1, "run in parallel using `N` goroutines (0: all CPUs)")
+	width := fs.Int("width", 600, "width of the SVG picture in pixels")
+	labels := fs.Bool("labels", false, "label the points of the SVG picture with their coordinates")
+	fs.Parse(os.Args[2:])
+	//
+	var run func([]pq.Point2q, io.Writer) error
+	o := &output{*out, *par, *width, *labels}
+	switch cmd {
+	case "hull":
+		run = o.hull
+	case "mincircle":
+		run = o.mincircle
+	case "closest":
+		run = o.closest
+	default:
+		usage()
+	}
+	switch *out {
+	case "text", "wkt", "geojson", "json", "svg":
+	default:
+		fatal(errors.New("unknown output format " + *out))
+	}
+	ps, err := readfiles(fs.Args(), *in)
+	if err != nil {
+		fatal(err)
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if err := run(ps, w); err != nil {
+		fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: pq hull|mincircle|closest [-in format] [-out format] [-par N] [file ...]")
+	fmt.Fprintln(os.Stderr, "run 'pq command -h' for the flags")
+	os.Exit(2)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "pq:", err)
+	os.Exit(1)
+}
+
+// readfiles reads the points from the files, or from the standard input if there are none.
+func readfiles(names []string, format string) ([]pq.Point2q, error) {
+	switch format {
+	case "auto", "csv", "ws", "wkt":
+	default:
+		return nil, errors.New("unknown input format " + format)
+	}
+	if len(names) == 0 {
+		return readpoints(os.Stdin, "<stdin>", format)
+	}
+	var ps []pq.Point2q
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		qs, err := readpoints(f, name, format)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, qs...)
+	}
+	return ps, nil
+}
+
+// readpoints reads the points from r in the given input format.
+func readpoints(r io.Reader, name, format string) ([]pq.Point2q, error) {
+	ps := make([]pq.Point2q, 0)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<26)
+	first := true
+	for k := 1; sc.Scan(); k++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		header := first && format == "csv"
+		first = false
+		var qs []pq.Point2q
+		var err error
+		switch {
+		case format == "wkt" || format == "auto" && iswkt(line):
+			qs, err = readwkt(line)
+		default:
+			var a pq.Point2q
+			a, err = readxy(line, format)
+			qs = []pq.Point2q{a}
+			//
+			// Skip a csv header line, i.e., a first line without numeric fields.
+			//
+			if err != nil && header && isheader(line) {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, k, err)
+		}
+		ps = append(ps, qs...)
+	}
+	return ps, sc.Err()
+}
+
+// isheader reports whether none of the comma-separated fields of line is a number.
+func isheader(line string) bool {
+	for _, f := range strings.Split(line, ",") {
+		if _, err := pq.ParseQ(strings.TrimSpace(f)); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// iswkt reports whether line starts with a supported WKT geometry type.
+func iswkt(line string) bool {
+	u := strings.ToUpper(line)
+	if strings.HasPrefix(u, "SRID=") {
+		return true
+	}
+	for _, tag := range []string{"POINT", "MULTIPOINT", "LINESTRING", "POLYGON"} {
+		if strings.HasPrefix(u, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// readwkt returns the points of the WKT geometry line: the point, the points
+// of a multipoint or a polyline, or the vertices of a polygon.
+func readwkt(line string) ([]pq.Point2q, error) {
+	u := strings.ToUpper(line)
+	if i := strings.IndexByte(u, ';'); i >= 0 && strings.HasPrefix(u, "SRID=") {
+		u = strings.TrimSpace(u[i+1:])
+	}
+	switch {
+	case strings.HasPrefix(u, "POINT"):
+		a, err := pq.WKTtoP(line)
+		return []pq.Point2q{a}, err
+	case strings.HasPrefix(u, "POLYGON"):
+		outer, holes, err := pq.WKTtoPoly(line)
+		ps := outer.Vertices()
+		for _, h := range holes {
+			ps = append(ps, h.Vertices()...)
+		}
+		return ps, err
+	}
+	return pq.WKTtoPs(line)
+}
+
+// readxy returns the point "x,y" (csv) or "x y" (ws); auto accepts both.
+func readxy(line, format string) (pq.Point2q, error) {
+	var f []string
+	switch format {
+	case "csv":
+		f = strings.Split(line, ",")
+	case "ws":
+		f = strings.Fields(line)
+	default:
+		f = strings.FieldsFunc(line, func(c rune) bool { return c == ',' || c == ' ' || c == '\t' })
+	}
+	if len(f) != 2 {
+		return pq.Point2q{}, errors.New("expected 2 coordinates")
+	}
+	x, err := pq.ParseQ(strings.TrimSpace(f[0]))
+	if err != nil {
+		return pq.Point2q{}, err
+	}
+	y, err := pq.ParseQ(strings.TrimSpace(f[1]))
+	if err != nil {
+		return pq.Point2q{}, err
+	}
+	return pq.XYtoP(x, y), nil
+}
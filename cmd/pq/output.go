@@ -0,0 +1,179 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/reconditematter/pq"
+)
+
+// output writes the results of the commands in the format; par is the -par flag.
+type output struct {
+	format string
+	par    int
+	width  int
+	labels bool
+}
+
+// xy returns "x y" with exact coordinates.
+func xy(a pq.Point2q) string {
+	return pq.FormatQ(a.X()) + " " + pq.FormatQ(a.Y())
+}
+
+// writejson writes v as JSON followed by a newline.
+func writejson(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// writeln writes the lines.
+func writeln(w io.Writer, lines ...string) error {
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func (o *output) hull(ps []pq.Point2q, w io.Writer) error {
+	all := append([]pq.Point2q{}, ps...)
+	var lower, upper []pq.Point2q
+	if o.par < 0 {
+		lower, upper = pq.ConvHull2q(ps)
+	} else {
+		lower, upper = pq.ParConvHull2q(o.par, ps)
+	}
+	hull := pq.HulltoPoly(lower, upper)
+	vs := hull.Vertices()
+	switch o.format {
+	case "wkt":
+		//
+		// A degenerate hull is a point or a segment.
+		//
+		switch len(vs) {
+		case 0:
+			return writeln(w, "POLYGON EMPTY")
+		case 1:
+			return writeln(w, vs[0].WKT())
+		case 2:
+			return writeln(w, pq.LineStringtoWKT(vs))
+		}
+		return writeln(w, hull.WKT())
+	case "geojson":
+		switch len(vs) {
+		case 1:
+			return writeln(w, string(pq.PtoGeoJSON(vs[0])))
+		case 2:
+			return writeln(w, string(pq.LineStringtoGeoJSON(vs)))
+		}
+		return writeln(w, string(pq.PolygontoGeoJSON(hull)))
+	case "json":
+		return writejson(w, vs)
+	case "svg":
+		s, err := pq.NewSVGErr(o.width, o.labels)
+		if err != nil {
+			return err
+		}
+		s.Points(all, "")
+		s.Hull(lower, upper, "")
+		_, err = w.Write(s.Bytes())
+		return err
+	}
+	lines := make([]string, len(vs))
+	for i, a := range vs {
+		lines[i] = xy(a)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return writeln(w, lines...)
+}
+
+func (o *output) mincircle(ps []pq.Point2q, w io.Writer) error {
+	if len(ps) == 0 {
+		return errors.New("no points")
+	}
+	all := append([]pq.Point2q{}, ps...)
+	var c pq.Circle2q
+	if o.par < 0 {
+		c = pq.MinCircle2q(ps)
+	} else {
+		c = pq.ParMinCircle2q(o.par, ps)
+	}
+	switch o.format {
+	case "wkt":
+		//
+		// The circle passes through one of the input points.
+		//
+		if c.Radius2().Sgn() == 0 {
+			return writeln(w, c.Center().WKT())
+		}
+		for _, a := range all {
+			if c.Side(a) == 0 {
+				return writeln(w, pq.CirtoWKT(c, a))
+			}
+		}
+		return errors.New("no input point on the circle")
+	case "geojson":
+		//
+		// A degenerate circle is a point, as in WKT.
+		//
+		if c.Radius2().Sgn() == 0 {
+			return writeln(w, string(pq.PtoGeoJSON(c.Center())))
+		}
+		return writeln(w, string(pq.CirtoGeoJSON(c, 64)))
+	case "json":
+		return writejson(w, c)
+	case "svg":
+		s, err := pq.NewSVGErr(o.width, o.labels)
+		if err != nil {
+			return err
+		}
+		s.MinCircle(all, "")
+		_, err = w.Write(s.Bytes())
+		return err
+	}
+	return writeln(w, xy(c.Center())+" "+pq.FormatQ(c.Radius2()))
+}
+
+func (o *output) closest(ps []pq.Point2q, w io.Writer) error {
+	if len(ps) < 2 {
+		return errors.New("fewer than two points")
+	}
+	all := append([]pq.Point2q{}, ps...)
+	var a, b pq.Point2q
+	var d2 pq.Q
+	if o.par < 0 {
+		a, b, d2 = pq.ClosestPair2q(ps)
+	} else {
+		a, b, d2 = pq.ParClosestPair2q(o.par, ps)
+	}
+	switch o.format {
+	case "wkt":
+		return writeln(w, pq.LineStringtoWKT([]pq.Point2q{a, b}))
+	case "geojson":
+		return writeln(w, string(pq.LineStringtoGeoJSON([]pq.Point2q{a, b})))
+	case "json":
+		return writejson(w, struct {
+			A     pq.Point2q `json:"a"`
+			B     pq.Point2q `json:"b"`
+			Dist2 pq.Q       `json:"dist2"`
+		}{a, b, d2})
+	case "svg":
+		s, err := pq.NewSVGErr(o.width, o.labels)
+		if err != nil {
+			return err
+		}
+		s.Points(all, "")
+		s.Polyline([]pq.Point2q{a, b}, "stroke:red;stroke-width:2")
+		s.Points([]pq.Point2q{a, b}, "fill:red")
+		_, err = w.Write(s.Bytes())
+		return err
+	}
+	return writeln(w, xy(a), xy(b), pq.FormatQ(d2))
+}
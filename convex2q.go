@@ -0,0 +1,276 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import "sort"
+
+// ConvexPolygon2q represents a convex polygon in the 2-dimensional Euclidean plane
+// supporting O(log n) queries. The vertices are listed in counter-clockwise order,
+// and no three of them are collinear.
+type ConvexPolygon2q struct {
+	vs []Point2q
+}
+
+// HulltoConv returns the convex polygon with the vertices of the lower hull and the upper hull
+// computed by ConvHull2q. The polygon may be degenerate, i.e., a point or a segment.
+func HulltoConv(lower, upper []Point2q) ConvexPolygon2q {
+	return ConvexPolygon2q{HulltoPoly(lower, upper).vs}
+}
+
+// Polygon returns c as a polygon.
+func (c ConvexPolygon2q) Polygon() Polygon2q {
+	return PtoPoly(c.vs)
+}
+
+// Side returns:
+//
+//	-1 if a is outside c
+//	 0 if a is on the boundary of c
+//	+1 if a is inside c
+func (c ConvexPolygon2q) Side(a Point2q) int {
+	vs := c.vs
+	n := len(vs)
+	if n < 3 {
+		if n == 0 {
+			return -1
+		}
+		if (Segment2q{vs[0], vs[n-1]}).Contains(a) {
+			return 0
+		}
+		return -1
+	}
+	//
+	// Locate a in the fan of triangles (v0,vk,vk+1).
+	//
+	v0 := vs[0]
+	o1, on := v0.Orientation(vs[1], a), v0.Orientation(vs[n-1], a)
+	if o1 < 0 || on > 0 {
+		return -1
+	}
+	k := sort.Search(n-2, func(k int) bool { return v0.Orientation(vs[k+2], a) < 0 })
+	k++
+	o := vs[k].Orientation(vs[(k+1)%n], a)
+	switch {
+	case o < 0:
+		return -1
+	case o == 0:
+		if (Segment2q{vs[k], vs[(k+1)%n]}).Contains(a) {
+			return 0
+		}
+		return -1
+	case o1 == 0 || on == 0:
+		return 0
+	}
+	return +1
+}
+
+// cmpccw compares the counter-clockwise angles from ref to u and from ref to v, measured in [0,2π).
+func cmpccw(ref, u, v Vector2q) int {
+	half := func(d Vector2q) int {
+		det := Det2x2(ref.x, ref.y, d.x, d.y).Sgn()
+		if det > 0 || (det == 0 && ref.Dot(d).Sgn() > 0) {
+			return 0
+		}
+		return 1
+	}
+	hu, hv := half(u), half(v)
+	if hu != hv {
+		if hu < hv {
+			return -1
+		}
+		return +1
+	}
+	return -Det2x2(u.x, u.y, v.x, v.y).Sgn()
+}
+
+// extreme returns the index of a vertex of c maximizing u·v, n ≥ 3.
+func (c ConvexPolygon2q) extreme(u Vector2q) int {
+	vs := c.vs
+	n := len(vs)
+	//
+	// The edge directions are sorted by angle. The maximum is attained at the vertex
+	// where the direction of the boundary passes the direction u rotated by 90°.
+	//
+	e0 := vs[0].Vector(vs[1])
+	t := XYtoV(u.y.Neg(), u.x)
+	k := sort.Search(n, func(k int) bool {
+		return cmpccw(e0, vs[k].Vector(vs[(k+1)%n]), t) >= 0
+	})
+	return k % n
+}
+
+// Extreme returns a vertex of c that is extreme in the direction u, i.e., it maximizes u·v.
+// If c has no vertices, a run-time panic occurs.
+func (c ConvexPolygon2q) Extreme(u Vector2q) Point2q {
+	vs := c.vs
+	if len(vs) == 0 {
+		panic(ErrEmpty)
+	}
+	if len(vs) < 3 {
+		if u.Dot(vs[0].Vector(vs[len(vs)-1])).Sgn() > 0 {
+			return vs[len(vs)-1]
+		}
+		return vs[0]
+	}
+	return vs[c.extreme(u)]
+}
+
+// ExtremeErr is like Extreme, but it returns ErrEmpty if c has no vertices.
+func (c ConvexPolygon2q) ExtremeErr(u Vector2q) (Point2q, error) {
+	if len(c.vs) == 0 {
+		return Point2q{}, ErrEmpty
+	}
+	return c.Extreme(u), nil
+}
+
+// Tangents returns the vertices of c where the tangent lines from a touch c: c lies to the left
+// of the line from a through left, and to the right of the line from a through right.
+// If a is collinear with an edge of c, then the vertex of this edge nearer to a is returned.
+// If a is not outside c, then ok=false.
+//
+// The edges of c visible from a form a chain, whose ends are the tangent points. The chain is
+// located by binary searches between a visible edge and an edge which is not visible.
+func (c ConvexPolygon2q) Tangents(a Point2q) (left, right Point2q, ok bool) {
+	vs := c.vs
+	n := len(vs)
+	if n == 0 || c.Side(a) >= 0 {
+		return Point2q{}, Point2q{}, false
+	}
+	if n < 3 {
+		l, r := vs[0], vs[n-1]
+		switch o := a.Orientation(l, r); {
+		case o < 0:
+			l, r = r, l
+		case o == 0:
+			if a.Dist2(r).Cmp(a.Dist2(l)) < 0 {
+				l = r
+			}
+			r = l
+		}
+		return l, r, true
+	}
+	at := func(i int) Point2q { return vs[(i%n+n)%n] }
+	//
+	// vis(i) the edge (vi,vi+1) is visible from a, i.e., a is strictly to the right of it.
+	//
+	vis := func(i int) bool { return at(i).Orientation(at(i+1), a) < 0 }
+	//
+	// Find a visible edge j in the fan of triangles (v0,vk,vk+1).
+	//
+	var j int
+	switch {
+	case vs[0].Orientation(vs[1], a) < 0:
+		j = 0
+	case vs[0].Orientation(vs[n-1], a) > 0:
+		j = n - 1
+	default:
+		k := sort.Search(n-2, func(k int) bool { return vs[0].Orientation(vs[k+2], a) < 0 })
+		j = k + 1
+		if !vis(j) {
+			//
+			// a is on the line of the edge j beyond one of its ends.
+			//
+			if vis(j - 1) {
+				j--
+			} else {
+				j++
+			}
+		}
+	}
+	//
+	// The visible edges turn by less than π, hence one of the edges at the vertex extreme
+	// in the direction opposite to the outer normal of the edge j is not visible.
+	//
+	e := at(j).Vector(at(j + 1))
+	m := c.extreme(XYtoV(e.y.Neg(), e.x))
+	if m < j {
+		m += n
+	}
+	if vis(m) {
+		m--
+	}
+	//
+	// The edges j..r-1 and l..j+n-1 are visible; the edges r..l-1 are not.
+	//
+	r := j + sort.Search(m-j, func(k int) bool { return !vis(j + k) })
+	l := m + sort.Search(j+n-m, func(k int) bool { return vis(m + k) })
+	return at(r), at(l), true
+}
+
+// Stab computes the intersection of c with the line through a and b, a≠b. It returns ok=false
+// if they do not intersect; otherwise the intersection is the segment [p,q], p≤q in (x,y)-order,
+// which is a single point p=q if the line touches c at a vertex.
+func (c ConvexPolygon2q) Stab(a, b Point2q) (p, q Point2q, ok bool) {
+	vs := c.vs
+	n := len(vs)
+	f := func(i int) int { return a.Orientation(b, vs[(i%n+n)%n]) }
+	sorted := func(p, q Point2q) (Point2q, Point2q, bool) {
+		if p.CmpXY(q) > 0 {
+			p, q = q, p
+		}
+		return p, q, true
+	}
+	//
+	// cross(i,j) the intersection of the line with the segment [vi,vj].
+	//
+	cross := func(i, j int) Point2q {
+		s := Segment2q{vs[(i%n+n)%n], vs[(j%n+n)%n]}
+		if f(i) == 0 {
+			return s.a
+		}
+		if f(j) == 0 {
+			return s.b
+		}
+		u, v, w := s.a.Vector(s.b), a.Vector(b), s.a.Vector(a)
+		lambda := Det2x2(w.x, w.y, v.x, v.y).Div(Det2x2(u.x, u.y, v.x, v.y))
+		return s.a.Add(u.Mul(lambda))
+	}
+	if n < 3 {
+		if n == 0 || f(0)*f(n-1) > 0 {
+			return Point2q{}, Point2q{}, false
+		}
+		if f(0) == 0 && f(n-1) == 0 {
+			return sorted(vs[0], vs[n-1])
+		}
+		x := cross(0, n-1)
+		return x, x, true
+	}
+	//
+	// The vertices with the minimum and the maximum signed distance to the line.
+	//
+	u := a.Vector(b)
+	nrm := XYtoV(u.y.Neg(), u.x)
+	imax, imin := c.extreme(nrm), c.extreme(nrm.Neg())
+	fmax, fmin := f(imax), f(imin)
+	if fmax < 0 || fmin > 0 {
+		return Point2q{}, Point2q{}, false
+	}
+	//
+	// The line supports c: the intersection is a vertex or an edge.
+	//
+	if fmax == 0 || fmin == 0 {
+		i := imax
+		if fmin == 0 {
+			i = imin
+		}
+		p, q = vs[i], vs[i]
+		for _, j := range []int{i - 1, i + 1} {
+			if f(j) == 0 {
+				q = vs[(j%n+n)%n]
+			}
+		}
+		return sorted(p, q)
+	}
+	//
+	// f increases along the chain from imin to imax, and decreases from imax to imin.
+	//
+	if imax < imin {
+		imax += n
+	}
+	k := imin + sort.Search(imax-imin, func(k int) bool { return f(imin+k) >= 0 })
+	p = cross(k-1, k)
+	imin += n
+	k = imax + sort.Search(imin-imax, func(k int) bool { return f(imax+k) <= 0 })
+	q = cross(k-1, k)
+	return sorted(p, q)
+}
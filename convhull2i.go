@@ -0,0 +1,22 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// ConvHull2i computes the convex hull of a collection of points with integer coordinates in the plane.
+// It implements Graham's scan algorithm with Andrew's modification. It computes
+// both the lower hull and the upper hull. The hull vertices are listed in
+// counter-clockwise order. The function modifies the input ps by reordering it.
+// The results are the same as those of ConvHull2q applied to the points converted by ToQ.
+//
+// Reference: R.L. Graham, An efficient algorithm for determining the convex hull of a
+// finite planar set, Inform. Process. Lett., 1:132-133 (1972).
+//
+// See: http://dx.doi.org/10.1016/0020-0190(72)90045-2
+//
+// Reference: A.M. Andrew, Another efficient algorithm for convex hulls in two dimensions,
+// Inform. Process. Lett., 9:216-219 (1979).
+//
+// See: http://dx.doi.org/10.1016/0020-0190(79)90072-3
+func ConvHull2i(ps []Point2i) (lower, upper []Point2i) {
+	return ConvHull2(ps)
+}
@@ -4,7 +4,6 @@ package pq
 
 import (
 	"runtime"
-	"sort"
 	"sync"
 )
 
@@ -23,74 +22,7 @@ import (
 //
 // See: http://dx.doi.org/10.1016/0020-0190(79)90072-3
 func ConvHull2q(ps []Point2q) (lower, upper []Point2q) {
-	//
-	// Two special cases: n=0 or n=1.
-	//
-	n := len(ps)
-	if n == 0 {
-		lower, upper = []Point2q{}, []Point2q{}
-		return
-	}
-	if n == 1 {
-		lower, upper = []Point2q{ps[0]}, []Point2q{ps[0]}
-		return
-	}
-	//
-	// Sort the input in (x,y)-order.
-	//
-	sort.Sort(p2qs(ps))
-	//
-	// noccw(list,p) (list[n-2],list[n-1],p) are not counter-clockwise.
-	//
-	noccw := func(list []Point2q, p Point2q) bool {
-		n := len(list)
-		return list[n-2].Orientation(list[n-1], p) <= 0
-	}
-	//
-	// del(list) returns list without its last element.
-	//
-	del := func(list []Point2q) []Point2q {
-		n1 := len(list) - 1
-		list[n1] = Point2q{}
-		return list[:n1]
-	}
-	//
-	// Build the lower hull.
-	//
-	lower = make([]Point2q, 0)
-	for i := 0; i < n; i++ {
-		pi := ps[i]
-		for len(lower) > 1 && noccw(lower, pi) {
-			lower = del(lower)
-		}
-		lower = append(lower, pi)
-	}
-	//
-	// Build the upper hull.
-	//
-	upper = make([]Point2q, 0)
-	for i := n - 1; i >= 0; i-- {
-		pi := ps[i]
-		for len(upper) > 1 && noccw(upper, pi) {
-			upper = del(upper)
-		}
-		upper = append(upper, pi)
-	}
-	//
-	// Special case.
-	//
-	if len(lower) == 2 && lower[0].CmpXY(lower[1]) == 0 {
-		lower[1] = Point2q{}
-		lower = lower[:1]
-	}
-	if len(upper) == 2 && upper[0].CmpXY(upper[1]) == 0 {
-		upper[1] = Point2q{}
-		upper = upper[:1]
-	}
-	//
-	//
-	//
-	return
+	return ConvHull2(ps)
 }
 
 // Sort interface implementation.
@@ -0,0 +1,17 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// ConvHull3i computes the convex hull of a collection of points with integer coordinates in the
+// 3-dimensional space. It implements the incremental algorithm with the points added in (x,y,z)-order,
+// then it merges the coplanar triangles into facets; all the tests are evaluated in integer arithmetic.
+// The function modifies the input ps by reordering it. The results are the same as those of ConvHull3q
+// applied to the points converted by ToQ, see ConvHull3q for the description of vs and fs.
+//
+// Reference: M. Kallay, The complexity of incremental convex hull algorithms in Rd,
+// Inform. Process. Lett., 19:197 (1984).
+//
+// See: http://dx.doi.org/10.1016/0020-0190(84)90084-X
+func ConvHull3i(ps []Point3i) (vs []Point3i, fs [][]int) {
+	return ConvHull3(ps)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ConvHull3q computes the convex hull of a collection of points in the 3-dimensional space.
+// It implements the incremental algorithm with the points added in (x,y,z)-order, then it
+// merges the coplanar triangles into facets. The function returns the hull vertices vs
+// in (x,y,z)-order and the hull facets fs. Each facet is a convex polygon given by the indices
+// of its vertices into vs; the vertices are listed in counter-clockwise order when viewed
+// from outside the hull, starting from the smallest index. The facets are sorted
+// lexicographically. Points lying in the interior of facets or edges are not hull vertices.
+// The function modifies the input ps by reordering it.
+//
+// Degenerate cases: if the points are coplanar, then the hull is a polygon reported as
+// two facets with opposite orientations; if the points are collinear, then vs contains
+// the two endpoints and fs is empty.
+//
+// Reference: M. Kallay, The complexity of incremental convex hull algorithms in Rd,
+// Inform. Process. Lett., 19:197 (1984).
+//
+// See: http://dx.doi.org/10.1016/0020-0190(84)90084-X
+func ConvHull3q(ps []Point3q) (vs []Point3q, fs [][]int) {
+	return ConvHull3(ps)
+}
+
+// ParConvHull3q computes the convex hull of a collection of points in the 3-dimensional space.
+// It implements the incremental algorithm with the points added in (x,y,z)-order, then it
+// merges the coplanar triangles into facets. The results are the same as those of ConvHull3q.
+// The function modifies the input ps by reordering it.
+// If ncpu > 0 then computations run in parallel using ncpu goroutines;
+// otherwise computations run in parallel using runtime.NumCPU() goroutines.
+//
+// Reference: M. Kallay, The complexity of incremental convex hull algorithms in Rd,
+// Inform. Process. Lett., 19:197 (1984).
+//
+// See: http://dx.doi.org/10.1016/0020-0190(84)90084-X
+func ParConvHull3q(ncpu int, ps []Point3q) (vs []Point3q, fs [][]int) {
+	if ncpu <= 0 {
+		ncpu = runtime.NumCPU()
+	}
+	n := len(ps)
+	//
+	// No need to parallelize.
+	//
+	if n < ncpu {
+		return ConvHull3q(ps)
+	}
+	//
+	// Use mu to synchronize appending results to coll.
+	//
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	coll := make([]Point3q, 0)
+	//
+	// Parallel loop.
+	//
+	for cpu := 0; cpu < ncpu; cpu++ {
+		wg.Add(1)
+		first, limit := cpu*n/ncpu, (cpu+1)*n/ncpu
+		go func() {
+			defer wg.Done()
+			vs, _ := ConvHull3q(ps[first:limit])
+			mu.Lock()
+			coll = append(coll, vs...)
+			mu.Unlock()
+		}()
+	}
+	//
+	// Wait for all goroutines to finish.
+	//
+	wg.Wait()
+	//
+	// The hull of the partial hull vertices is the hull of ps.
+	//
+	return ConvHull3q(coll)
+}
+
+// Sort interface implementation.
+type p3qs []Point3q
+
+func (a p3qs) Len() int           { return len(a) }
+func (a p3qs) Less(i, j int) bool { return a[i].CmpXYZ(a[j]) < 0 }
+func (a p3qs) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
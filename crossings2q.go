@@ -0,0 +1,228 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"container/heap"
+	"math/rand"
+	"sort"
+)
+
+// Crossing2q represents a point where two or more segments intersect.
+type Crossing2q struct {
+	p    Point2q
+	segs []int
+}
+
+// Point returns the intersection point of c.
+func (c Crossing2q) Point() Point2q {
+	return c.p
+}
+
+// Segments returns the indices of the segments passing through c.Point() in increasing order.
+func (c Crossing2q) Segments() []int {
+	return c.segs
+}
+
+// Crossings2q computes all intersection points of a collection of segments in the plane.
+// It implements the Bentley-Ottmann plane sweep algorithm in (x,y)-order with exact
+// comparisons, and it handles vertical, degenerate, touching and overlapping segments.
+// A crossing is reported for every point common to at least two segments, except that
+// for overlapping collinear segments only the endpoints of their common sub-segment
+// are reported. The crossings are listed in (x,y)-order of their points.
+//
+// Reference: J.L. Bentley, T.A. Ottmann, Algorithms for reporting and counting geometric
+// intersections, IEEE Trans. Comput., C-28:643-647 (1979).
+//
+// See: http://dx.doi.org/10.1109/TC.1979.1675432
+//
+// Reference: M. de Berg, O. Cheong, M. van Kreveld, M. Overmars, Computational Geometry:
+// Algorithms and Applications, 3rd ed., Springer, pp 20-29 (2008).
+func Crossings2q(ss []Segment2q) []Crossing2q {
+	//
+	// lo[i] and hi[i] are the endpoints of ss[i] in (x,y)-order.
+	//
+	n := len(ss)
+	lo := make([]Point2q, n)
+	hi := make([]Point2q, n)
+	events := make(events2q, 0, 2*n)
+	for i, s := range ss {
+		lo[i], hi[i] = s.a, s.b
+		if lo[i].CmpXY(hi[i]) > 0 {
+			lo[i], hi[i] = hi[i], lo[i]
+		}
+		events = append(events, event2q{lo[i], []int{i}}, event2q{hi[i], nil})
+	}
+	heap.Init(&events)
+	//
+	// The status holds the segments crossing the sweep line ordered from below to above.
+	//
+	var status *status2q
+	res := make([]Crossing2q, 0)
+	//
+	// push(s,t,p) schedules the intersection of ss[s] and ss[t] if it is beyond p.
+	//
+	push := func(s, t int, p Point2q) {
+		kind, x, _ := ss[s].Intersection(ss[t])
+		if (kind == SegProper || kind == SegTouch) && x.CmpXY(p) > 0 {
+			heap.Push(&events, event2q{x, nil})
+		}
+	}
+	for events.Len() > 0 {
+		//
+		// Pop the next event point p and the segments U starting at p.
+		//
+		ev := heap.Pop(&events).(event2q)
+		p, U := ev.p, ev.segs
+		for events.Len() > 0 && events[0].p.CmpXY(p) == 0 {
+			U = append(U, heap.Pop(&events).(event2q).segs...)
+		}
+		//
+		// Split the status into the segments below p, the block C of the segments containing p,
+		// and the segments above p.
+		//
+		below, rest := status.split(func(s int) bool { return lo[s].Orientation(hi[s], p) <= 0 })
+		block, above := rest.split(func(s int) bool { return lo[s].Orientation(hi[s], p) < 0 })
+		C := block.segs(make([]int, 0))
+		//
+		// Report p.
+		//
+		if len(U)+len(C) > 1 {
+			segs := make([]int, 0, len(U)+len(C))
+			segs = append(segs, U...)
+			segs = append(segs, C...)
+			sort.Ints(segs)
+			res = append(res, Crossing2q{p, segs})
+		}
+		//
+		// Replace the block with the segments continuing beyond p, ordered by slope.
+		//
+		next := make([]int, 0, len(U)+len(C))
+		for _, s := range append(U, C...) {
+			if hi[s].CmpXY(p) > 0 {
+				next = append(next, s)
+			}
+		}
+		sort.Slice(next, func(a, b int) bool {
+			s, t := next[a], next[b]
+			if o := p.Orientation(hi[s], hi[t]); o != 0 {
+				return o > 0
+			}
+			return s < t
+		})
+		var mid *status2q
+		for _, s := range next {
+			mid = mid.join(&status2q{seg: s, prio: rand.Int63()})
+		}
+		//
+		// Check the new neighbors for intersections beyond p.
+		//
+		if mid == nil {
+			if below != nil && above != nil {
+				push(below.last(), above.first(), p)
+			}
+		} else {
+			if below != nil {
+				push(below.last(), mid.first(), p)
+			}
+			if above != nil {
+				push(mid.last(), above.first(), p)
+			}
+		}
+		status = below.join(mid).join(above)
+	}
+	return res
+}
+
+// status2q is a treap holding the segments crossing the sweep line in its in-order sequence.
+// The empty treap is nil.
+//
+// Reference: R. Seidel, C.R. Aragon, Randomized search trees, Algorithmica, 16:464-497 (1996).
+//
+// See: http://dx.doi.org/10.1007/BF01940876
+type status2q struct {
+	seg   int
+	prio  int64
+	left  *status2q
+	right *status2q
+}
+
+// split splits t into the segments s with above(s) false and the segments with above(s) true;
+// above must be monotone along t.
+func (t *status2q) split(above func(s int) bool) (l, r *status2q) {
+	if t == nil {
+		return nil, nil
+	}
+	if above(t.seg) {
+		l, t.left = t.left.split(above)
+		return l, t
+	}
+	t.right, r = t.right.split(above)
+	return t, r
+}
+
+// join returns the concatenation of t and u.
+func (t *status2q) join(u *status2q) *status2q {
+	if t == nil {
+		return u
+	}
+	if u == nil {
+		return t
+	}
+	if t.prio > u.prio {
+		t.right = t.right.join(u)
+		return t
+	}
+	u.left = t.join(u.left)
+	return u
+}
+
+// segs appends the segments of t to res.
+func (t *status2q) segs(res []int) []int {
+	if t == nil {
+		return res
+	}
+	res = t.left.segs(res)
+	res = append(res, t.seg)
+	return t.right.segs(res)
+}
+
+// first returns the first segment of t, which must not be empty.
+func (t *status2q) first() int {
+	for t.left != nil {
+		t = t.left
+	}
+	return t.seg
+}
+
+// last returns the last segment of t, which must not be empty.
+func (t *status2q) last() int {
+	for t.right != nil {
+		t = t.right
+	}
+	return t.seg
+}
+
+// event2q represents an event of the plane sweep: a point and the segments starting at it.
+type event2q struct {
+	p    Point2q
+	segs []int
+}
+
+// Heap interface implementation.
+type events2q []event2q
+
+func (a events2q) Len() int           { return len(a) }
+func (a events2q) Less(i, j int) bool { return a[i].p.CmpXY(a[j].p) < 0 }
+func (a events2q) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+func (a *events2q) Push(x interface{}) { *a = append(*a, x.(event2q)) }
+
+func (a *events2q) Pop() interface{} {
+	old := *a
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = event2q{}
+	*a = old[:n-1]
+	return x
+}
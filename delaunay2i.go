@@ -0,0 +1,23 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// Delaunay2i computes the Delaunay triangulation of a collection of points with integer coordinates
+// in the plane. The initial triangulation is built by a plane sweep in (x,y)-order, then it is made
+// Delaunay by Lawson's edge flipping; all the tests are evaluated in integer arithmetic, and only
+// the vertices of the result are converted to Point2q. The function modifies the input ps
+// by reordering it. The results are the same as those of Delaunay2q applied to the points
+// converted by ToQ.
+//
+// Reference: C.L. Lawson, Software for C¹ surface interpolation,
+// Mathematical Software III, pp 161-194 (1977).
+//
+// See: http://hdl.handle.net/2060/19770025881
+func Delaunay2i(ps []Point2i) Triangulation2q {
+	vs, tri, adj := Delaunay2(ps)
+	qs := make([]Point2q, len(vs))
+	for i, p := range vs {
+		qs[i] = p.ToQ()
+	}
+	return Triangulation2q{qs, tri, adj}
+}
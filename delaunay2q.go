@@ -0,0 +1,45 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// Triangulation2q represents a triangulation of a finite set of points in the plane.
+type Triangulation2q struct {
+	ps  []Point2q
+	tri [][3]int
+	adj [][3]int
+}
+
+// Points returns the vertices of t. The vertices are distinct and listed in (x,y)-order.
+func (t Triangulation2q) Points() []Point2q {
+	return t.ps
+}
+
+// Triangles returns the triangles of t. Each triangle is a triple of indices into t.Points(),
+// and its vertices are listed in counter-clockwise order.
+func (t Triangulation2q) Triangles() [][3]int {
+	return t.tri
+}
+
+// Neighbors returns the adjacency of the triangles of t. Neighbors()[k][i] is the index of
+// the triangle sharing with Triangles()[k] the edge opposite to its i-th vertex,
+// or -1 if this edge lies on the convex hull.
+func (t Triangulation2q) Neighbors() [][3]int {
+	return t.adj
+}
+
+// Delaunay2q computes the Delaunay triangulation of a collection of points in the plane.
+// The initial triangulation is built by a plane sweep in (x,y)-order, then it is made
+// Delaunay by Lawson's edge flipping. Duplicate points are triangulated once.
+// If all points are collinear, then the triangulation has no triangles.
+// Cocircular points are triangulated deterministically: an edge is flipped only if
+// the opposite vertex lies strictly inside the circle. The function modifies the input ps
+// by reordering it.
+//
+// Reference: C.L. Lawson, Software for C¹ surface interpolation,
+// Mathematical Software III, pp 161-194 (1977).
+//
+// See: http://hdl.handle.net/2060/19770025881
+func Delaunay2q(ps []Point2q) Triangulation2q {
+	vs, tri, adj := Delaunay2(ps)
+	return Triangulation2q{vs, tri, adj}
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import "errors"
+
+// Errors returned by the constructors and the algorithms. The functions whose names end with Err
+// return these errors; the corresponding functions without the suffix panic with them instead,
+// e.g., FtoQErr returns ErrNotFinite, while FtoQ panics with ErrNotFinite. The errors can be
+// tested with errors.Is, also after recovering from a panic.
+var (
+	ErrNotFinite      = errors.New("pq: not finite")
+	ErrNegativeRadius = errors.New("pq: negative radius squared")
+	ErrCollinear      = errors.New("pq: collinear points")
+	ErrCoplanar       = errors.New("pq: coplanar points")
+	ErrEmpty          = errors.New("pq: empty input")
+	ErrTooFew         = errors.New("pq: fewer than two points")
+	ErrZeroArea       = errors.New("pq: zero area")
+	ErrNotOnCircle    = errors.New("pq: point not on circle")
+	ErrHoleOutside    = errors.New("pq: hole outside of outer rings")
+	ErrNotSimple      = errors.New("pq: not a simple polygon")
+	ErrInexact        = errors.New("pq: not exactly representable")
+	ErrInvalid        = errors.New("pq: invalid argument")
+)
+
+// must returns v if err is nil; otherwise it panics with err.
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
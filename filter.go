@@ -0,0 +1,182 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import "math"
+
+// Floating-point filters. A predicate is first evaluated in float64 together with
+// an upper bound on the rounding error. The exact rational evaluation is performed
+// only if the bound does not certify the sign of the float64 result.
+//
+// Reference: J.R. Shewchuk, Adaptive precision floating-point arithmetic and fast robust
+// geometric predicates, Discrete Comput. Geom., 18:305-363 (1997).
+//
+// See: http://dx.doi.org/10.1007/PL00009321
+
+const (
+	epsf  = 0x1p-53   // the unit roundoff of float64
+	minf  = 0x1p-1022 // the smallest positive normal float64
+	tinyf = 0x1p-960  // an absolute error bound covering underflow
+)
+
+// f64 returns x rounded to float64. It returns ok=false if the relative error
+// of the rounding may exceed epsf, i.e., if x overflows or underflows.
+func (x Q) f64() (f float64, ok bool) {
+	f = x.Float64()
+	a := math.Abs(f)
+	if math.IsInf(f, 0) {
+		return f, false
+	}
+	if a < minf {
+		return f, a == 0 && x.Sgn() == 0
+	}
+	return f, true
+}
+
+// orient2f evaluates a.Orientation(b,c) in float64.
+// It returns ok=false if the result is not certified.
+func orient2f(a, b, c Point2q) (sgn int, ok bool) {
+	var ax, ay, bx, by, cx, cy float64
+	if ax, ok = a.x.f64(); !ok {
+		return
+	}
+	if ay, ok = a.y.f64(); !ok {
+		return
+	}
+	if bx, ok = b.x.f64(); !ok {
+		return
+	}
+	if by, ok = b.y.f64(); !ok {
+		return
+	}
+	if cx, ok = c.x.f64(); !ok {
+		return
+	}
+	if cy, ok = c.y.f64(); !ok {
+		return
+	}
+	return orient2ff(ax, ay, bx, by, cx, cy)
+}
+
+// f64 returns a rounded to float64, see Q.f64.
+func (a Point2q) f64() (p Point2f, ok bool) {
+	if p.x, ok = a.x.f64(); !ok {
+		return
+	}
+	p.y, ok = a.y.f64()
+	return
+}
+
+// incircle2f evaluates a.InCircle(b,c,d) in float64.
+// It returns ok=false if the result is not certified.
+func incircle2f(a, b, c, d Point2q) (sgn int, ok bool) {
+	var af, bf, cf, df Point2f
+	if af, ok = a.f64(); !ok {
+		return
+	}
+	if bf, ok = b.f64(); !ok {
+		return
+	}
+	if cf, ok = c.f64(); !ok {
+		return
+	}
+	if df, ok = d.f64(); !ok {
+		return
+	}
+	return incircle2ff(af, bf, cf, df)
+}
+
+// indiametral2f evaluates a.InDiametral(b,c) in float64.
+// It returns ok=false if the result is not certified.
+func indiametral2f(a, b, c Point2q) (sgn int, ok bool) {
+	var af, bf, cf Point2f
+	if af, ok = a.f64(); !ok {
+		return
+	}
+	if bf, ok = b.f64(); !ok {
+		return
+	}
+	if cf, ok = c.f64(); !ok {
+		return
+	}
+	sgn, ok = dot2ff(af, bf, cf)
+	return -sgn, ok
+}
+
+// orient2ff evaluates the orientation of the points (ax,ay), (bx,by) and (cx,cy) in float64.
+// It returns ok=false if the result is not certified.
+func orient2ff(ax, ay, bx, by, cx, cy float64) (sgn int, ok bool) {
+	det := (bx-ax)*(cy-ay) - (by-ay)*(cx-ax)
+	mag := (math.Abs(bx)+math.Abs(ax))*(math.Abs(cy)+math.Abs(ay)) +
+		(math.Abs(by)+math.Abs(ay))*(math.Abs(cx)+math.Abs(ax))
+	return certify(det, 8*epsf*mag+tinyf)
+}
+
+// incircle2ff evaluates a.InCircle(b,c,d) for the points with float64 coordinates in float64.
+// It returns ok=false if the result is not certified. The error bound also covers the rounding
+// of the coordinates to float64 by incircle2f.
+func incircle2ff(a, b, c, d Point2f) (sgn int, ok bool) {
+	adx, ady := a.x-d.x, a.y-d.y
+	bdx, bdy := b.x-d.x, b.y-d.y
+	cdx, cdy := c.x-d.x, c.y-d.y
+	ad2 := adx*adx + ady*ady
+	bd2 := bdx*bdx + bdy*bdy
+	cd2 := cdx*cdx + cdy*cdy
+	det := adx*(bdy*cd2-cdy*bd2) - ady*(bdx*cd2-cdx*bd2) + ad2*(bdx*cdy-cdx*bdy)
+	//
+	// The permanent of the matrix of the bounds |p|+|d| on the differences.
+	//
+	sx := func(p Point2f) float64 { return math.Abs(p.x) + math.Abs(d.x) }
+	sy := func(p Point2f) float64 { return math.Abs(p.y) + math.Abs(d.y) }
+	sl := func(p Point2f) float64 { return sx(p)*sx(p) + sy(p)*sy(p) }
+	mag := sx(a)*(sy(b)*sl(c)+sy(c)*sl(b)) + sy(a)*(sx(b)*sl(c)+sx(c)*sl(b)) + sl(a)*(sx(b)*sy(c)+sx(c)*sy(b))
+	return certify(det, 32*epsf*mag+tinyf)
+}
+
+// dot2ff evaluates the sign of (a-c)·(b-c) for the points with float64 coordinates in float64.
+// It returns ok=false if the result is not certified.
+func dot2ff(a, b, c Point2f) (sgn int, ok bool) {
+	det := (a.x-c.x)*(b.x-c.x) + (a.y-c.y)*(b.y-c.y)
+	mag := (math.Abs(a.x)+math.Abs(c.x))*(math.Abs(b.x)+math.Abs(c.x)) +
+		(math.Abs(a.y)+math.Abs(c.y))*(math.Abs(b.y)+math.Abs(c.y))
+	return certify(det, 8*epsf*mag+tinyf)
+}
+
+// side2f evaluates c.Side(a) in float64.
+// It returns ok=false if the result is not certified.
+func side2f(c Circle2q, a Point2q) (sgn int, ok bool) {
+	var cx, cy, rsq, ax, ay float64
+	if cx, ok = c.cen.x.f64(); !ok {
+		return
+	}
+	if cy, ok = c.cen.y.f64(); !ok {
+		return
+	}
+	if rsq, ok = c.rsq.f64(); !ok {
+		return
+	}
+	if ax, ok = a.x.f64(); !ok {
+		return
+	}
+	if ay, ok = a.y.f64(); !ok {
+		return
+	}
+	dx, dy := cx-ax, cy-ay
+	det := rsq - (dx*dx + dy*dy)
+	sx, sy := math.Abs(cx)+math.Abs(ax), math.Abs(cy)+math.Abs(ay)
+	return certify(det, 8*epsf*(sx*sx+sy*sy+math.Abs(rsq))+tinyf)
+}
+
+// certify returns the sign of det if |det| exceeds the error bound err.
+func certify(det, err float64) (sgn int, ok bool) {
+	if math.IsInf(err, 0) || math.IsNaN(err) {
+		return 0, false
+	}
+	if det > err {
+		return +1, true
+	}
+	if det < -err {
+		return -1, true
+	}
+	return 0, false
+}
@@ -0,0 +1,338 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GeoJSON representation of geometry, see RFC 7946, The GeoJSON Format.
+//
+// The supported geometries are Point, MultiPoint, LineString, Polygon and MultiPolygon.
+// The coordinates are read exactly: a JSON number such as 0.1 or 1e-30 is converted to Q
+// by ParseQ without a float64 round-trip. A position may have an altitude, which is ignored.
+// A geometry is read from a geometry object or from a Feature.
+//
+// A coordinate is written as an exact decimal number if its decimal expansion terminates
+// (the denominator is 2^a·5^b), e.g. 12.375 or -0.1; otherwise it is written as the nearest
+// float64, since JSON numbers are decimal. The rings of polygons are closed and follow
+// the right-hand rule, i.e., the outer rings are counter-clockwise and the holes are clockwise.
+
+var errCirVertices = fmt.Errorf("%w: fewer than three vertices", ErrInvalid)
+
+// PtoGeoJSON returns the GeoJSON Point a.
+func PtoGeoJSON(a Point2q) []byte {
+	return geojsongeom("Point", geopos(a))
+}
+
+// MultiPointtoGeoJSON returns the GeoJSON MultiPoint ps.
+func MultiPointtoGeoJSON(ps []Point2q) []byte {
+	return geojsongeom("MultiPoint", geoposs(ps, false))
+}
+
+// LineStringtoGeoJSON returns the GeoJSON LineString ps.
+func LineStringtoGeoJSON(ps []Point2q) []byte {
+	return geojsongeom("LineString", geoposs(ps, false))
+}
+
+// PolygontoGeoJSON returns the GeoJSON Polygon with the outer ring outer and the given holes.
+// A convex hull computed by ConvHull2q is written by PolygontoGeoJSON(HulltoPoly(lower, upper)).
+func PolygontoGeoJSON(outer Polygon2q, holes ...Polygon2q) []byte {
+	if len(outer.vs) == 0 {
+		return geojsongeom("Polygon", "[]")
+	}
+	return geojsongeom("Polygon", georings(PolytoReg(outer, holes...).rings))
+}
+
+// RegtoGeoJSON returns the GeoJSON MultiPolygon r. Each hole of r is assigned to the smallest
+// outer ring containing it. If a hole is not contained in an outer ring, a run-time panic occurs.
+func RegtoGeoJSON(r Region2q) []byte {
+	return must(RegtoGeoJSONErr(r))
+}
+
+// RegtoGeoJSONErr is like RegtoGeoJSON, but it returns ErrHoleOutside if a hole of r
+// is not contained in an outer ring.
+func RegtoGeoJSONErr(r Region2q) ([]byte, error) {
+	var outers []int
+	for i, p := range r.rings {
+		if p.Orientation() > 0 {
+			outers = append(outers, i)
+		}
+	}
+	polys := make([][]Polygon2q, len(outers))
+	for k, i := range outers {
+		polys[k] = []Polygon2q{r.rings[i]}
+	}
+	for _, h := range r.rings {
+		if h.Orientation() > 0 {
+			continue
+		}
+		best := -1
+		for k, i := range outers {
+			o := r.rings[i]
+			in := true
+			for _, v := range h.vs {
+				if o.Side(v) < 0 {
+					in = false
+					break
+				}
+			}
+			if in && (best < 0 || o.Area().Cmp(polys[best][0].Area()) < 0) {
+				best = k
+			}
+		}
+		if best < 0 {
+			return nil, ErrHoleOutside
+		}
+		polys[best] = append(polys[best], h)
+	}
+	s := make([]string, len(polys))
+	for k, rings := range polys {
+		s[k] = georings(rings)
+	}
+	return geojsongeom("MultiPolygon", "["+strings.Join(s, ",")+"]"), nil
+}
+
+// TrianglestoGeoJSON returns the GeoJSON MultiPolygon of the triangles tris with vertices
+// from ps, e.g., a triangulation computed by Delaunay2q or Triangulate2q.
+func TrianglestoGeoJSON(ps []Point2q, tris [][3]int) []byte {
+	s := make([]string, len(tris))
+	for k, t := range tris {
+		s[k] = "[" + geoposs([]Point2q{ps[t[0]], ps[t[1]], ps[t[2]]}, true) + "]"
+	}
+	return geojsongeom("MultiPolygon", "["+strings.Join(s, ",")+"]")
+}
+
+// CirtoGeoJSON returns a GeoJSON Feature representing c: the geometry is the Polygon with n
+// vertices on c, which are rounded to the shortest decimals identifying float64 numbers,
+// and the properties "center" and "radius2" are the exact center and radius squared of c
+// encoded as by MarshalJSON. If n < 3, a run-time panic occurs.
+func CirtoGeoJSON(c Circle2q, n int) []byte {
+	return must(CirtoGeoJSONErr(c, n))
+}
+
+// CirtoGeoJSONErr is like CirtoGeoJSON, but it returns an error wrapping ErrInvalid if n < 3.
+func CirtoGeoJSONErr(c Circle2q, n int) ([]byte, error) {
+	if n < 3 {
+		return nil, errCirVertices
+	}
+	cx, cy, r := c.cen.x.Float64(), c.cen.y.Float64(), math.Sqrt(c.rsq.Float64())
+	vs := make([]Point2q, n)
+	for k := range vs {
+		sin, cos := math.Sincos(2 * math.Pi * float64(k) / float64(n))
+		x, _ := ParseQ(strconv.FormatFloat(cx+r*cos, 'g', -1, 64))
+		y, _ := ParseQ(strconv.FormatFloat(cy+r*sin, 'g', -1, 64))
+		vs[k] = Point2q{x, y}
+	}
+	center, _ := c.cen.MarshalJSON()
+	radius2, _ := c.rsq.MarshalJSON()
+	return []byte(`{"type":"Feature","geometry":` + string(PolygontoGeoJSON(Polygon2q{vs})) +
+		`,"properties":{"center":` + string(center) + `,"radius2":` + string(radius2) + `}}`), nil
+}
+
+// geojsongeom returns the geometry object with the given type and coordinates.
+func geojsongeom(typ, coords string) []byte {
+	return []byte(`{"type":"` + typ + `","coordinates":` + coords + `}`)
+}
+
+// geopos returns the position [x,y].
+func geopos(a Point2q) string {
+	return "[" + geonum(a.x) + "," + geonum(a.y) + "]"
+}
+
+// geoposs returns the array of positions ps; if closed, then the first point is repeated at the end.
+func geoposs(ps []Point2q, closed bool) string {
+	if closed && len(ps) > 0 {
+		ps = append(ps[:len(ps):len(ps)], ps[0])
+	}
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = geopos(p)
+	}
+	return "[" + strings.Join(s, ",") + "]"
+}
+
+// georings returns the array of the closed rings.
+func georings(rings []Polygon2q) string {
+	s := make([]string, len(rings))
+	for i, p := range rings {
+		s[i] = geoposs(p.vs, true)
+	}
+	return "[" + strings.Join(s, ",") + "]"
+}
+
+// geonum returns x as a JSON number, see decimal.
+func geonum(x Q) string {
+	if s, ok := decimal(x); ok {
+		return s
+	}
+	return strconv.FormatFloat(x.Float64(), 'g', -1, 64)
+}
+
+// GeoJSONtoP returns the point represented by the GeoJSON Point data.
+func GeoJSONtoP(data []byte) (Point2q, error) {
+	_, c, err := parsegeojson(data, "Point")
+	if err != nil {
+		return Point2q{}, err
+	}
+	return geotoP(c)
+}
+
+// GeoJSONtoPs returns the points represented by the GeoJSON MultiPoint or LineString data.
+func GeoJSONtoPs(data []byte) ([]Point2q, error) {
+	_, c, err := parsegeojson(data, "MultiPoint", "LineString")
+	if err != nil {
+		return nil, err
+	}
+	return geotoPs(c)
+}
+
+// GeoJSONtoPoly returns the polygon with holes represented by the GeoJSON Polygon data.
+// The rings must be closed; the repeated last point is removed.
+func GeoJSONtoPoly(data []byte) (outer Polygon2q, holes []Polygon2q, err error) {
+	_, c, err := parsegeojson(data, "Polygon")
+	if err != nil {
+		return Polygon2q{}, nil, err
+	}
+	rings, err := geotorings(c)
+	if err != nil || len(rings) == 0 {
+		return Polygon2q{}, nil, err
+	}
+	return rings[0], rings[1:], nil
+}
+
+// GeoJSONtoReg returns the region represented by the GeoJSON Polygon or MultiPolygon data.
+// The orientations of the rings are normalized.
+func GeoJSONtoReg(data []byte) (Region2q, error) {
+	typ, c, err := parsegeojson(data, "Polygon", "MultiPolygon")
+	if err != nil {
+		return Region2q{}, err
+	}
+	polys := []interface{}{c}
+	if typ == "MultiPolygon" {
+		if polys, err = geoarray(c); err != nil {
+			return Region2q{}, err
+		}
+	}
+	res := Region2q{make([]Polygon2q, 0)}
+	for _, p := range polys {
+		rings, err := geotorings(p)
+		if err != nil {
+			return Region2q{}, err
+		}
+		if len(rings) > 0 {
+			res.rings = append(res.rings, PolytoReg(rings[0], rings[1:]...).rings...)
+		}
+	}
+	return res, nil
+}
+
+// parsegeojson returns the type and the coordinates of the geometry object data, or of the
+// geometry of the Feature data. The type must be one of types. The coordinates are decoded
+// as nested []interface{} with json.Number elements.
+func parsegeojson(data []byte, types ...string) (string, interface{}, error) {
+	var g struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+		Geometry    json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		return "", nil, err
+	}
+	if g.Type == "Feature" {
+		if len(g.Geometry) == 0 || string(g.Geometry) == "null" {
+			return "", nil, errors.New("pq: GeoJSON: feature without geometry")
+		}
+		return parsegeojson(g.Geometry, types...)
+	}
+	ok := false
+	for _, t := range types {
+		ok = ok || g.Type == t
+	}
+	if !ok {
+		return "", nil, errors.New("pq: GeoJSON: expected " + strings.Join(types, " or ") + ", got " + strconv.Quote(g.Type))
+	}
+	if len(g.Coordinates) == 0 {
+		return "", nil, errors.New("pq: GeoJSON: missing coordinates")
+	}
+	var c interface{}
+	d := json.NewDecoder(bytes.NewReader(g.Coordinates))
+	d.UseNumber()
+	if err := d.Decode(&c); err != nil {
+		return "", nil, err
+	}
+	return g.Type, c, nil
+}
+
+// geoarray returns c as an array.
+func geoarray(c interface{}) ([]interface{}, error) {
+	a, ok := c.([]interface{})
+	if !ok {
+		return nil, errors.New("pq: GeoJSON: expected an array of coordinates")
+	}
+	return a, nil
+}
+
+// geotoP returns the point at the position c; an altitude is ignored.
+func geotoP(c interface{}) (Point2q, error) {
+	a, err := geoarray(c)
+	if err != nil {
+		return Point2q{}, err
+	}
+	if len(a) < 2 {
+		return Point2q{}, errors.New("pq: GeoJSON: a position must have at least 2 coordinates")
+	}
+	var xy [2]Q
+	for k := range xy {
+		num, ok := a[k].(json.Number)
+		if !ok {
+			return Point2q{}, errors.New("pq: GeoJSON: a coordinate must be a number")
+		}
+		if xy[k], err = ParseQ(string(num)); err != nil {
+			return Point2q{}, err
+		}
+	}
+	return Point2q{xy[0], xy[1]}, nil
+}
+
+// geotoPs returns the points at the array of positions c.
+func geotoPs(c interface{}) ([]Point2q, error) {
+	a, err := geoarray(c)
+	if err != nil {
+		return nil, err
+	}
+	ps := make([]Point2q, len(a))
+	for i := range a {
+		if ps[i], err = geotoP(a[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ps, nil
+}
+
+// geotorings returns the polygons with the closed rings c without the repeated last points.
+func geotorings(c interface{}) ([]Polygon2q, error) {
+	a, err := geoarray(c)
+	if err != nil {
+		return nil, err
+	}
+	rings := make([]Polygon2q, len(a))
+	for i := range a {
+		vs, err := geotoPs(a[i])
+		if err != nil {
+			return nil, err
+		}
+		n := len(vs)
+		if n == 0 || vs[0].CmpXY(vs[n-1]) != 0 {
+			return nil, errors.New("pq: GeoJSON: polygon ring is not closed")
+		}
+		rings[i] = Polygon2q{vs[:n-1]}
+	}
+	return rings, nil
+}
@@ -0,0 +1,397 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Point2 is the geometry kernel of the generic algorithms in the plane: the point type P
+// with the predicates they are built on. The implementations are:
+//
+//	Point2q  exact rational coordinates
+//	Point2f  float64 coordinates with filtered exact predicates
+//	Point2i  int64 coordinates with integer predicates
+//
+// The predicates must be exact, so that the algorithms give the same results with all kernels.
+type Point2[P any] interface {
+	// CmpXY compares the points in xy-order.
+	CmpXY(b P) int
+	// Orientation returns the orientation of (a,b,c) as Point2q.Orientation.
+	Orientation(b, c P) int
+	// InCircle returns the position of d with respect to the circle through (a,b,c) as Point2q.InCircle.
+	InCircle(b, c, d P) int
+	// InDiametral returns the position of c with respect to the circle with the diameter [a,b]
+	// as Point2q.InDiametral.
+	InDiametral(b, c P) int
+}
+
+// ConvHull2 computes the convex hull of a collection of points in the plane using the kernel P.
+// It implements Graham's scan algorithm with Andrew's modification. It computes
+// both the lower hull and the upper hull. The hull vertices are listed in
+// counter-clockwise order. The function modifies the input ps by reordering it.
+//
+// Reference: R.L. Graham, An efficient algorithm for determining the convex hull of a
+// finite planar set, Inform. Process. Lett., 1:132-133 (1972).
+//
+// See: http://dx.doi.org/10.1016/0020-0190(72)90045-2
+//
+// Reference: A.M. Andrew, Another efficient algorithm for convex hulls in two dimensions,
+// Inform. Process. Lett., 9:216-219 (1979).
+//
+// See: http://dx.doi.org/10.1016/0020-0190(79)90072-3
+func ConvHull2[P Point2[P]](ps []P) (lower, upper []P) {
+	//
+	// Two special cases: n=0 or n=1.
+	//
+	n := len(ps)
+	if n == 0 {
+		lower, upper = []P{}, []P{}
+		return
+	}
+	if n == 1 {
+		lower, upper = []P{ps[0]}, []P{ps[0]}
+		return
+	}
+	//
+	// Sort the input in (x,y)-order.
+	//
+	sort.Slice(ps, func(i, j int) bool { return ps[i].CmpXY(ps[j]) < 0 })
+	//
+	// noccw(list,p) (list[n-2],list[n-1],p) are not counter-clockwise.
+	//
+	noccw := func(list []P, p P) bool {
+		n := len(list)
+		return list[n-2].Orientation(list[n-1], p) <= 0
+	}
+	//
+	// del(list) returns list without its last element.
+	//
+	del := func(list []P) []P {
+		var zero P
+		n1 := len(list) - 1
+		list[n1] = zero
+		return list[:n1]
+	}
+	//
+	// Build the lower hull.
+	//
+	lower = make([]P, 0)
+	for i := 0; i < n; i++ {
+		pi := ps[i]
+		for len(lower) > 1 && noccw(lower, pi) {
+			lower = del(lower)
+		}
+		lower = append(lower, pi)
+	}
+	//
+	// Build the upper hull.
+	//
+	upper = make([]P, 0)
+	for i := n - 1; i >= 0; i-- {
+		pi := ps[i]
+		for len(upper) > 1 && noccw(upper, pi) {
+			upper = del(upper)
+		}
+		upper = append(upper, pi)
+	}
+	//
+	// Special case.
+	//
+	if len(lower) == 2 && lower[0].CmpXY(lower[1]) == 0 {
+		lower = del(lower)
+	}
+	if len(upper) == 2 && upper[0].CmpXY(upper[1]) == 0 {
+		upper = del(upper)
+	}
+	return
+}
+
+// MinCircle2 computes the smallest enclosing circle of a collection of points in the plane using
+// the kernel P. It implements Welzl's randomized algorithm applied to the convex hull of a given
+// collection of points. The circle is represented by the points defining it: a single point,
+// two points spanning a diameter, or three points on the circle. If ps is empty, a run-time
+// panic occurs. The function modifies the input ps by reordering it.
+//
+// Reference: E. Welzl, Smallest enclosing disks (balls and ellipsoids),
+// Lecture Notes in Computer Science Volume 555, pp 359-370 (1991).
+//
+// See: http://dx.doi.org/10.1007/BFb0038202
+func MinCircle2[P Point2[P]](ps []P) []P {
+	return mindisc0(hullpts(ConvHull2(ps)))
+}
+
+// MinCircle2Err is like MinCircle2, but it returns ErrEmpty if ps is empty.
+func MinCircle2Err[P Point2[P]](ps []P) ([]P, error) {
+	if len(ps) == 0 {
+		return nil, ErrEmpty
+	}
+	return MinCircle2(ps), nil
+}
+
+// hullpts returns the vertices of the hull given by the lower hull and the upper hull.
+func hullpts[P any](lower, upper []P) []P {
+	var zero P
+	if len(lower) > 1 {
+		lower[len(lower)-1] = zero
+		lower = lower[:len(lower)-1]
+	}
+	if len(upper) > 1 {
+		upper[len(upper)-1] = zero
+		upper = upper[:len(upper)-1]
+	}
+	chull := make([]P, 0)
+	chull = append(chull, lower...)
+	chull = append(chull, upper...)
+	return chull
+}
+
+// disc2 represents the circle through the points ps[:n], see MinCircle2.
+type disc2[P Point2[P]] struct {
+	ps [3]P
+	n  int
+}
+
+// out reports whether a is outside d.
+func (d disc2[P]) out(a P) bool {
+	p := d.ps
+	switch d.n {
+	case 1:
+		return a.CmpXY(p[0]) != 0
+	case 2:
+		return p[0].InDiametral(p[1], a) < 0
+	}
+	return p[0].InCircle(p[1], p[2], a)*p[0].Orientation(p[1], p[2]) < 0
+}
+
+func mindisc0[P Point2[P]](ps []P) []P {
+	n := len(ps)
+	if n == 0 {
+		panic(ErrEmpty)
+	}
+	if n == 1 {
+		return []P{ps[0]}
+	}
+	//
+	shuffle := func(ps []P) {
+		for k := len(ps) - 1; k >= 0; k-- {
+			i := rand.Intn(k + 1)
+			ps[k], ps[i] = ps[i], ps[k]
+		}
+	}
+	//
+	shuffle(ps)
+	D := disc2[P]{[3]P{ps[0], ps[1]}, 2}
+	for k := 2; k < n; k++ {
+		pk := ps[k]
+		if D.out(pk) {
+			D = mindisc1(ps[:k], pk)
+		}
+	}
+	return append([]P{}, D.ps[:D.n]...)
+}
+
+func mindisc1[P Point2[P]](ps []P, q P) disc2[P] {
+	D := disc2[P]{[3]P{ps[0], q}, 2}
+	n := len(ps)
+	for k := 1; k < n; k++ {
+		pk := ps[k]
+		if D.out(pk) {
+			D = mindisc2(ps[:k], pk, q)
+		}
+	}
+	return D
+}
+
+func mindisc2[P Point2[P]](ps []P, q1, q2 P) disc2[P] {
+	D := disc2[P]{[3]P{q1, q2}, 2}
+	n := len(ps)
+	for k := 0; k < n; k++ {
+		pk := ps[k]
+		if D.out(pk) {
+			D = disc2[P]{[3]P{q1, q2, pk}, 3}
+		}
+	}
+	return D
+}
+
+// Delaunay2 computes the Delaunay triangulation of a collection of points in the plane using
+// the kernel P. It returns the vertices vs, the triangles tri and their adjacency adj as described
+// for Triangulation2q.
+// The initial triangulation is built by a plane sweep in (x,y)-order, then it is made
+// Delaunay by Lawson's edge flipping. Duplicate points are triangulated once.
+// If all points are collinear, then the triangulation has no triangles.
+// Cocircular points are triangulated deterministically: an edge is flipped only if
+// the opposite vertex lies strictly inside the circle. The function modifies the input ps
+// by reordering it.
+//
+// Reference: C.L. Lawson, Software for C¹ surface interpolation,
+// Mathematical Software III, pp 161-194 (1977).
+//
+// See: http://hdl.handle.net/2060/19770025881
+func Delaunay2[P Point2[P]](ps []P) (vs []P, tri, adj [][3]int) {
+	//
+	// Sort the input in (x,y)-order and drop duplicates.
+	//
+	sort.Slice(ps, func(i, j int) bool { return ps[i].CmpXY(ps[j]) < 0 })
+	vs = make([]P, 0, len(ps))
+	for i := range ps {
+		if i == 0 || ps[i].CmpXY(ps[i-1]) != 0 {
+			vs = append(vs, ps[i])
+		}
+	}
+	tri, adj = [][3]int{}, [][3]int{}
+	n := len(vs)
+	//
+	// Find the first point off the line through vs[0] and vs[1].
+	//
+	k := 2
+	for k < n && vs[0].Orientation(vs[1], vs[k]) == 0 {
+		k++
+	}
+	if k >= n {
+		return vs, tri, adj
+	}
+	//
+	// The convex hull is a counter-clockwise cycle: next[i] follows i, prev[i] precedes i.
+	//
+	next := make([]int, n)
+	prev := make([]int, n)
+	link := func(i, j int) {
+		next[i] = j
+		prev[j] = i
+	}
+	//
+	// Fan the collinear points vs[0..k-1] to vs[k].
+	//
+	ccw := vs[0].Orientation(vs[1], vs[k]) > 0
+	for i := 0; i+1 < k; i++ {
+		if ccw {
+			tri = append(tri, [3]int{i, i + 1, k})
+			link(i, i+1)
+		} else {
+			tri = append(tri, [3]int{i + 1, i, k})
+			link(i+1, i)
+		}
+	}
+	if ccw {
+		link(k-1, k)
+		link(k, 0)
+	} else {
+		link(0, k)
+		link(k, k-1)
+	}
+	//
+	// visible(i,p) the hull edge (i,next[i]) is strictly visible from p.
+	//
+	visible := func(i int, p P) bool {
+		return vs[i].Orientation(vs[next[i]], p) < 0
+	}
+	//
+	// Add the remaining points one by one. Each point is outside the current hull,
+	// and it is connected to the chain of hull edges visible from it.
+	//
+	for j := k + 1; j < n; j++ {
+		p := vs[j]
+		first := j - 1
+		for !visible(first, p) {
+			first = next[first]
+		}
+		for visible(prev[first], p) {
+			first = prev[first]
+		}
+		last := first
+		for visible(last, p) {
+			tri = append(tri, [3]int{next[last], last, j})
+			last = next[last]
+		}
+		link(first, j)
+		link(j, last)
+	}
+	//
+	// Compute the adjacency by matching each edge (a,b) with its twin (b,a).
+	//
+	type edge struct{ a, b int }
+	owner := make(map[edge]int, 3*len(tri))
+	for i, tr := range tri {
+		for e := 0; e < 3; e++ {
+			owner[edge{tr[(e+1)%3], tr[(e+2)%3]}] = i
+		}
+	}
+	adj = make([][3]int, len(tri))
+	for i, tr := range tri {
+		for e := 0; e < 3; e++ {
+			if u, ok := owner[edge{tr[(e+2)%3], tr[(e+1)%3]}]; ok {
+				adj[i][e] = u
+			} else {
+				adj[i][e] = -1
+			}
+		}
+	}
+	//
+	// Flip the edges that are not locally Delaunay.
+	//
+	flip2(vs, tri, adj)
+	return vs, tri, adj
+}
+
+// flip2 applies Lawson's edge flipping to the triangulation (vs,tri,adj) until all edges
+// are locally Delaunay.
+func flip2[P Point2[P]](vs []P, tri, adj [][3]int) {
+	//
+	// index(u,v) the position of v in the adjacency of u.
+	//
+	index := func(u, v int) int {
+		for e := 0; e < 3; e++ {
+			if adj[u][e] == v {
+				return e
+			}
+		}
+		return -1
+	}
+	//
+	// Initially all edges are suspect.
+	//
+	type item struct{ k, e int }
+	stack := make([]item, 0, 3*len(tri))
+	for k := range tri {
+		for e := 0; e < 3; e++ {
+			if adj[k][e] > k {
+				stack = append(stack, item{k, e})
+			}
+		}
+	}
+	for len(stack) > 0 {
+		it := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		k, i := it.k, it.e
+		u := adj[k][i]
+		if u < 0 {
+			continue
+		}
+		j := index(u, k)
+		a, b, c := tri[k][i], tri[k][(i+1)%3], tri[k][(i+2)%3]
+		d := tri[u][j]
+		if vs[a].InCircle(vs[b], vs[c], vs[d]) <= 0 {
+			continue
+		}
+		//
+		// Replace the edge (b,c) with the edge (a,d):
+		// (a,b,c)+(d,c,b) becomes (a,b,d)+(a,d,c).
+		//
+		nab, nca := adj[k][(i+2)%3], adj[k][(i+1)%3]
+		ndc, nbd := adj[u][(j+2)%3], adj[u][(j+1)%3]
+		tri[k] = [3]int{a, b, d}
+		adj[k] = [3]int{nbd, u, nab}
+		tri[u] = [3]int{a, d, c}
+		adj[u] = [3]int{ndc, nca, k}
+		if nbd >= 0 {
+			adj[nbd][index(nbd, u)] = k
+		}
+		if nca >= 0 {
+			adj[nca][index(nca, k)] = u
+		}
+		stack = append(stack, item{k, 0}, item{k, 2}, item{u, 0}, item{u, 1})
+	}
+}
@@ -0,0 +1,419 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Point3 is the geometry kernel of the generic algorithms in the 3-dimensional space: the point type P
+// with the predicates they are built on. The implementations are:
+//
+//	Point3q  exact rational coordinates
+//	Point3i  int64 coordinates with integer predicates
+//
+// The predicates must be exact, so that the algorithms give the same results with all kernels.
+type Point3[P any] interface {
+	// CmpXYZ compares the points in xyz-order.
+	CmpXYZ(b P) int
+	// Orientation returns the orientation of (a,b,c,d) as Point3q.Orientation.
+	Orientation(b, c, d P) int
+	// CoplanarOrientation returns the orientation of (a,b,c) in their plane as Point3q.CoplanarOrientation.
+	CoplanarOrientation(b, c P) int
+	// InSphere returns the position of e with respect to the sphere through (a,b,c,d) as Point3q.InSphere.
+	InSphere(b, c, d, e P) int
+	// InEquatorial returns the position of d with respect to the smallest sphere through (a,b,c)
+	// as Point3q.InEquatorial.
+	InEquatorial(b, c, d P) int
+	// InDiametral returns the position of c with respect to the sphere with the diameter [a,b]
+	// as Point3q.InDiametral.
+	InDiametral(b, c P) int
+}
+
+// ConvHull3 computes the convex hull of a collection of points in the 3-dimensional space
+// using the kernel P.
+// It implements the incremental algorithm with the points added in (x,y,z)-order, then it
+// merges the coplanar triangles into facets. The function returns the hull vertices vs
+// in (x,y,z)-order and the hull facets fs. Each facet is a convex polygon given by the indices
+// of its vertices into vs; the vertices are listed in counter-clockwise order when viewed
+// from outside the hull, starting from the smallest index. The facets are sorted
+// lexicographically. Points lying in the interior of facets or edges are not hull vertices.
+// The function modifies the input ps by reordering it.
+//
+// Degenerate cases: if the points are coplanar, then the hull is a polygon reported as
+// two facets with opposite orientations; if the points are collinear, then vs contains
+// the two endpoints and fs is empty.
+//
+// Reference: M. Kallay, The complexity of incremental convex hull algorithms in Rd,
+// Inform. Process. Lett., 19:197 (1984).
+//
+// See: http://dx.doi.org/10.1016/0020-0190(84)90084-X
+func ConvHull3[P Point3[P]](ps []P) (vs []P, fs [][]int) {
+	//
+	// Sort the input in (x,y,z)-order and drop duplicates.
+	//
+	sort.Slice(ps, func(i, j int) bool { return ps[i].CmpXYZ(ps[j]) < 0 })
+	qs := make([]P, 0, len(ps))
+	for i := range ps {
+		if i == 0 || ps[i].CmpXYZ(ps[i-1]) != 0 {
+			qs = append(qs, ps[i])
+		}
+	}
+	n := len(qs)
+	//
+	// Special cases: n<=2 or collinear points.
+	//
+	i2 := 2
+	for i2 < n && qs[0].CoplanarOrientation(qs[1], qs[i2]) == 0 {
+		i2++
+	}
+	if i2 >= n {
+		if n <= 1 {
+			return qs, [][]int{}
+		}
+		return []P{qs[0], qs[n-1]}, [][]int{}
+	}
+	//
+	// Special case: coplanar points.
+	//
+	i3 := i2 + 1
+	for i3 < n && qs[0].Orientation(qs[1], qs[i2], qs[i3]) == 0 {
+		i3++
+	}
+	if i3 >= n {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		poly := facet3(qs, [3]int{0, 1, i2}, all)
+		back := make([]int, len(poly))
+		for i := range poly {
+			back[i] = poly[(len(poly)-i)%len(poly)]
+		}
+		return hull3(qs, [][]int{poly, back})
+	}
+	//
+	// The hull is a list of triangles; owner maps a directed edge to its triangle.
+	//
+	type edge struct{ a, b int }
+	tri := make([][3]int, 0)
+	alive := make([]bool, 0)
+	owner := make(map[edge]int)
+	incident := make([]int, n)
+	add := func(a, b, c int) {
+		k := len(tri)
+		tri = append(tri, [3]int{a, b, c})
+		alive = append(alive, true)
+		owner[edge{a, b}] = k
+		owner[edge{b, c}] = k
+		owner[edge{c, a}] = k
+		incident[a], incident[b], incident[c] = k, k, k
+	}
+	//
+	// above(k,p) p is strictly above the triangle k.
+	//
+	above := func(k int, p P) bool {
+		t := tri[k]
+		return qs[t[0]].Orientation(qs[t[1]], qs[t[2]], p) > 0
+	}
+	//
+	// The initial tetrahedron.
+	//
+	i0, i1 := 0, 1
+	if qs[i0].Orientation(qs[i1], qs[i2], qs[i3]) > 0 {
+		i1, i2 = i2, i1
+	}
+	add(i0, i1, i2)
+	add(i0, i3, i1)
+	add(i1, i3, i2)
+	add(i2, i3, i0)
+	//
+	// Add the remaining points one by one. Each point is outside the current hull.
+	//
+	last := i3
+	for j := 2; j < n; j++ {
+		if j == i2 || j == i3 || j == i1 {
+			continue
+		}
+		p := qs[j]
+		//
+		// Find a visible triangle around the last added vertex, or anywhere.
+		//
+		seed := -1
+		k := incident[last]
+		for {
+			if above(k, p) {
+				seed = k
+				break
+			}
+			t := tri[k]
+			i := 0
+			for t[i] != last {
+				i++
+			}
+			k = owner[edge{last, t[(i+2)%3]}]
+			if k == incident[last] {
+				break
+			}
+		}
+		for k := 0; seed < 0 && k < len(tri); k++ {
+			if alive[k] && above(k, p) {
+				seed = k
+			}
+		}
+		if seed < 0 {
+			continue
+		}
+		//
+		// Remove the visible region and collect its horizon.
+		//
+		visible := map[int]bool{seed: true}
+		stack := []int{seed}
+		horizon := make([]edge, 0)
+		for len(stack) > 0 {
+			k := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			t := tri[k]
+			for i := 0; i < 3; i++ {
+				a, b := t[i], t[(i+1)%3]
+				u := owner[edge{b, a}]
+				if visible[u] {
+					continue
+				}
+				if above(u, p) {
+					visible[u] = true
+					stack = append(stack, u)
+				} else {
+					horizon = append(horizon, edge{a, b})
+				}
+			}
+		}
+		for k := range visible {
+			alive[k] = false
+		}
+		for _, e := range horizon {
+			add(e.a, e.b, j)
+		}
+		last = j
+	}
+	//
+	// Merge the coplanar triangles into facets.
+	//
+	group := make([]int, len(tri))
+	for k := range group {
+		group[k] = -1
+	}
+	fs = make([][]int, 0)
+	for k := range tri {
+		if !alive[k] || group[k] >= 0 {
+			continue
+		}
+		g := len(fs)
+		group[k] = g
+		t := tri[k]
+		stack := []int{k}
+		set := map[int]bool{}
+		for len(stack) > 0 {
+			h := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for i := 0; i < 3; i++ {
+				a, b := tri[h][i], tri[h][(i+1)%3]
+				set[a] = true
+				u := owner[edge{b, a}]
+				if group[u] < 0 && qs[t[0]].Orientation(qs[t[1]], qs[t[2]], qs[tri[u][2]]) == 0 &&
+					qs[t[0]].Orientation(qs[t[1]], qs[t[2]], qs[tri[u][0]]) == 0 &&
+					qs[t[0]].Orientation(qs[t[1]], qs[t[2]], qs[tri[u][1]]) == 0 {
+					group[u] = g
+					stack = append(stack, u)
+				}
+			}
+		}
+		idx := make([]int, 0, len(set))
+		for i := range set {
+			idx = append(idx, i)
+		}
+		sort.Ints(idx)
+		fs = append(fs, facet3(qs, t, idx))
+	}
+	return hull3(qs, fs)
+}
+
+// facet3 returns the vertices of the convex polygon spanned by the coplanar points qs[idx]
+// in counter-clockwise order when viewed from the side from which the triangle qs[t]
+// appears counter-clockwise.
+func facet3[P Point3[P]](qs []P, t [3]int, idx []int) []int {
+	//
+	// The orientation in the plane relative to the triangle t.
+	//
+	sgn := qs[t[0]].CoplanarOrientation(qs[t[1]], qs[t[2]])
+	orient := func(a, b, c int) int {
+		return sgn * qs[a].CoplanarOrientation(qs[b], qs[c])
+	}
+	//
+	// Andrew's monotone chain over the indices in (x,y,z)-order, which is the lexicographic
+	// order with respect to two independent directions in the plane.
+	//
+	sort.Slice(idx, func(i, j int) bool { return qs[idx[i]].CmpXYZ(qs[idx[j]]) < 0 })
+	chain := func(order []int) []int {
+		list := make([]int, 0)
+		for _, i := range order {
+			for len(list) > 1 && orient(list[len(list)-2], list[len(list)-1], i) <= 0 {
+				list = list[:len(list)-1]
+			}
+			list = append(list, i)
+		}
+		return list[:len(list)-1]
+	}
+	order := append([]int{}, idx...)
+	poly := chain(order)
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	return append(poly, chain(order)...)
+}
+
+// hull3 renumbers the facets fs to use only the hull vertices, and brings them into canonical order.
+func hull3[P any](qs []P, fs [][]int) ([]P, [][]int) {
+	renum := make([]int, len(qs))
+	for _, f := range fs {
+		for _, i := range f {
+			renum[i] = 1
+		}
+	}
+	vs := make([]P, 0)
+	for i, used := range renum {
+		if used != 0 {
+			renum[i] = len(vs)
+			vs = append(vs, qs[i])
+		}
+	}
+	for _, f := range fs {
+		m := 0
+		for i := range f {
+			f[i] = renum[f[i]]
+			if f[i] < f[m] {
+				m = i
+			}
+		}
+		rot := append(append([]int{}, f[m:]...), f[:m]...)
+		copy(f, rot)
+	}
+	sort.Slice(fs, func(i, j int) bool {
+		a, b := fs[i], fs[j]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return vs, fs
+}
+
+// MinSphere3 computes the smallest enclosing sphere of a collection of points in the 3-dimensional
+// space using the kernel P. It implements Welzl's randomized algorithm applied to the vertices of
+// the convex hull of a given collection of points. The sphere is represented by 1, 2, 3 or 4 points
+// lying on it: the sphere is the point itself, the sphere having the segment as its diameter,
+// the smallest sphere through the three points, or the sphere through the four points.
+// If ps is empty, a run-time panic occurs. The function modifies the input ps by reordering it.
+//
+// Reference: E. Welzl, Smallest enclosing disks (balls and ellipsoids),
+// Lecture Notes in Computer Science Volume 555, pp 359-370 (1991).
+//
+// See: http://dx.doi.org/10.1007/BFb0038202
+func MinSphere3[P Point3[P]](ps []P) []P {
+	vs, _ := ConvHull3(ps)
+	return minball0(vs)
+}
+
+// MinSphere3Err is like MinSphere3, but it returns ErrEmpty if ps is empty.
+func MinSphere3Err[P Point3[P]](ps []P) ([]P, error) {
+	if len(ps) == 0 {
+		return nil, ErrEmpty
+	}
+	return MinSphere3(ps), nil
+}
+
+// ball3 represents the sphere through the points ps[:n], see MinSphere3.
+type ball3[P Point3[P]] struct {
+	ps [4]P
+	n  int
+}
+
+// out reports whether a is outside d.
+func (d ball3[P]) out(a P) bool {
+	p := d.ps
+	switch d.n {
+	case 1:
+		return a.CmpXYZ(p[0]) != 0
+	case 2:
+		return p[0].InDiametral(p[1], a) < 0
+	case 3:
+		return p[0].InEquatorial(p[1], p[2], a) < 0
+	}
+	return p[0].InSphere(p[1], p[2], p[3], a)*p[0].Orientation(p[1], p[2], p[3]) < 0
+}
+
+func minball0[P Point3[P]](ps []P) []P {
+	n := len(ps)
+	if n == 0 {
+		panic(ErrEmpty)
+	}
+	if n == 1 {
+		return []P{ps[0]}
+	}
+	//
+	shuffle := func(ps []P) {
+		for k := len(ps) - 1; k >= 0; k-- {
+			i := rand.Intn(k + 1)
+			ps[k], ps[i] = ps[i], ps[k]
+		}
+	}
+	//
+	shuffle(ps)
+	D := ball3[P]{[4]P{ps[0], ps[1]}, 2}
+	for k := 2; k < n; k++ {
+		pk := ps[k]
+		if D.out(pk) {
+			D = minball1(ps[:k], pk)
+		}
+	}
+	return append([]P{}, D.ps[:D.n]...)
+}
+
+func minball1[P Point3[P]](ps []P, q P) ball3[P] {
+	D := ball3[P]{[4]P{ps[0], q}, 2}
+	n := len(ps)
+	for k := 1; k < n; k++ {
+		pk := ps[k]
+		if D.out(pk) {
+			D = minball2(ps[:k], pk, q)
+		}
+	}
+	return D
+}
+
+func minball2[P Point3[P]](ps []P, q1, q2 P) ball3[P] {
+	D := ball3[P]{[4]P{q1, q2}, 2}
+	n := len(ps)
+	for k := 0; k < n; k++ {
+		pk := ps[k]
+		if D.out(pk) {
+			D = minball3(ps[:k], pk, q1, q2)
+		}
+	}
+	return D
+}
+
+func minball3[P Point3[P]](ps []P, q1, q2, q3 P) ball3[P] {
+	D := ball3[P]{[4]P{q1, q2, q3}, 3}
+	n := len(ps)
+	for k := 0; k < n; k++ {
+		pk := ps[k]
+		if D.out(pk) {
+			D = ball3[P]{[4]P{q1, q2, q3, pk}, 4}
+		}
+	}
+	return D
+}
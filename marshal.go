@@ -0,0 +1,392 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Text, JSON and binary encodings. The encodings are exact: a rational number is never
+// converted to floating point.
+//
+//	Q         text "n/d", JSON string "n/d", binary big.Rat gob encoding
+//	Point2q   text "(x,y)", JSON array [x,y]
+//	Point3q   text "(x,y,z)", JSON array [x,y,z]
+//	Vector2q  text "(x,y)", JSON array [x,y]
+//	Vector3q  text "(x,y,z)", JSON array [x,y,z]
+//	Circle2q  text "((x,y),radius2)", JSON object {"center":[x,y],"radius2":r}
+//
+// The text encodings are the same as the results of String. A Q is decoded from any form
+// accepted by ParseQ, and from a JSON number, which is converted exactly. The binary encodings
+// of points, vectors and circles are the sequences of the binary encodings of their
+// coordinates, each one prefixed with its length as a uvarint.
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (x Q) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (x *Q) UnmarshalText(text []byte) error {
+	q, err := ParseQ(string(text))
+	if err != nil {
+		return err
+	}
+	*x = q
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (x Q) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (x *Q) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	d := json.NewDecoder(strings.NewReader(string(data)))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return x.UnmarshalText([]byte(v))
+	case json.Number:
+		q, err := ParseQ(string(v))
+		if err != nil {
+			return err
+		}
+		*x = q
+		return nil
+	}
+	return fmt.Errorf("pq: cannot unmarshal %s into Q", data)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (x Q) MarshalBinary() ([]byte, error) {
+	return r(x).GobEncode()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (x *Q) UnmarshalBinary(data []byte) error {
+	r := new(big.Rat)
+	if err := r.GobDecode(data); err != nil {
+		return err
+	}
+	*x = rtoq(r)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (a Point2q) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (a *Point2q) UnmarshalText(text []byte) error {
+	qs, err := unmarshaltuple(string(text), 2)
+	if err == nil {
+		*a = Point2q{qs[0], qs[1]}
+	}
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a Point2q) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]Q{a.x, a.y})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *Point2q) UnmarshalJSON(data []byte) error {
+	qs, err := unmarshalarray(data, 2)
+	if err == nil && qs != nil {
+		*a = Point2q{qs[0], qs[1]}
+	}
+	return err
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (a Point2q) MarshalBinary() ([]byte, error) {
+	return marshalqs(a.x, a.y)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (a *Point2q) UnmarshalBinary(data []byte) error {
+	qs, err := unmarshalqs(data, 2)
+	if err == nil {
+		*a = Point2q{qs[0], qs[1]}
+	}
+	return err
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (a Point3q) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (a *Point3q) UnmarshalText(text []byte) error {
+	qs, err := unmarshaltuple(string(text), 3)
+	if err == nil {
+		*a = Point3q{qs[0], qs[1], qs[2]}
+	}
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (a Point3q) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]Q{a.x, a.y, a.z})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *Point3q) UnmarshalJSON(data []byte) error {
+	qs, err := unmarshalarray(data, 3)
+	if err == nil && qs != nil {
+		*a = Point3q{qs[0], qs[1], qs[2]}
+	}
+	return err
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (a Point3q) MarshalBinary() ([]byte, error) {
+	return marshalqs(a.x, a.y, a.z)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (a *Point3q) UnmarshalBinary(data []byte) error {
+	qs, err := unmarshalqs(data, 3)
+	if err == nil {
+		*a = Point3q{qs[0], qs[1], qs[2]}
+	}
+	return err
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (u Vector2q) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (u *Vector2q) UnmarshalText(text []byte) error {
+	qs, err := unmarshaltuple(string(text), 2)
+	if err == nil {
+		*u = Vector2q{qs[0], qs[1]}
+	}
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (u Vector2q) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]Q{u.x, u.y})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (u *Vector2q) UnmarshalJSON(data []byte) error {
+	qs, err := unmarshalarray(data, 2)
+	if err == nil && qs != nil {
+		*u = Vector2q{qs[0], qs[1]}
+	}
+	return err
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (u Vector2q) MarshalBinary() ([]byte, error) {
+	return marshalqs(u.x, u.y)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (u *Vector2q) UnmarshalBinary(data []byte) error {
+	qs, err := unmarshalqs(data, 2)
+	if err == nil {
+		*u = Vector2q{qs[0], qs[1]}
+	}
+	return err
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (u Vector3q) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (u *Vector3q) UnmarshalText(text []byte) error {
+	qs, err := unmarshaltuple(string(text), 3)
+	if err == nil {
+		*u = Vector3q{qs[0], qs[1], qs[2]}
+	}
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (u Vector3q) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]Q{u.x, u.y, u.z})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (u *Vector3q) UnmarshalJSON(data []byte) error {
+	qs, err := unmarshalarray(data, 3)
+	if err == nil && qs != nil {
+		*u = Vector3q{qs[0], qs[1], qs[2]}
+	}
+	return err
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (u Vector3q) MarshalBinary() ([]byte, error) {
+	return marshalqs(u.x, u.y, u.z)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (u *Vector3q) UnmarshalBinary(data []byte) error {
+	qs, err := unmarshalqs(data, 3)
+	if err == nil {
+		*u = Vector3q{qs[0], qs[1], qs[2]}
+	}
+	return err
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (c Circle2q) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (c *Circle2q) UnmarshalText(text []byte) error {
+	s := string(text)
+	i := strings.IndexByte(s, ')')
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' || i < 0 || i+1 >= len(s) || s[i+1] != ',' {
+		return fmt.Errorf("pq: invalid circle %q", s)
+	}
+	var cen Point2q
+	if err := cen.UnmarshalText([]byte(s[1 : i+1])); err != nil {
+		return err
+	}
+	rsq, err := ParseQ(s[i+2 : len(s)-1])
+	if err != nil {
+		return err
+	}
+	return c.set(cen, rsq)
+}
+
+// set sets c to the circle with the center cen and radius squared rsq.
+func (c *Circle2q) set(cen Point2q, rsq Q) error {
+	d, err := CR2toCirErr(cen, rsq)
+	if err != nil {
+		return err
+	}
+	*c = d
+	return nil
+}
+
+// circle2qJSON is the JSON representation of Circle2q.
+type circle2qJSON struct {
+	Center  *Point2q `json:"center"`
+	Radius2 *Q       `json:"radius2"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c Circle2q) MarshalJSON() ([]byte, error) {
+	return json.Marshal(circle2qJSON{&c.cen, &c.rsq})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *Circle2q) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var v circle2qJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Center == nil || v.Radius2 == nil {
+		return errors.New("pq: missing center or radius2")
+	}
+	return c.set(*v.Center, *v.Radius2)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (c Circle2q) MarshalBinary() ([]byte, error) {
+	return marshalqs(c.cen.x, c.cen.y, c.rsq)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (c *Circle2q) UnmarshalBinary(data []byte) error {
+	qs, err := unmarshalqs(data, 3)
+	if err != nil {
+		return err
+	}
+	return c.set(Point2q{qs[0], qs[1]}, qs[2])
+}
+
+// unmarshaltuple parses the text "(q1,...,qn)".
+func unmarshaltuple(s string, n int) ([]Q, error) {
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return nil, fmt.Errorf("pq: invalid tuple %q", s)
+	}
+	fs := strings.Split(s[1:len(s)-1], ",")
+	if len(fs) != n {
+		return nil, fmt.Errorf("pq: %q has %d coordinates, want %d", s, len(fs), n)
+	}
+	qs := make([]Q, n)
+	for i, f := range fs {
+		q, err := ParseQ(f)
+		if err != nil {
+			return nil, err
+		}
+		qs[i] = q
+	}
+	return qs, nil
+}
+
+// unmarshalarray parses the JSON array [q1,...,qn]. It returns nil for the JSON null.
+func unmarshalarray(data []byte, n int) ([]Q, error) {
+	var qs []Q
+	if err := json.Unmarshal(data, &qs); err != nil {
+		return nil, err
+	}
+	if qs != nil && len(qs) != n {
+		return nil, fmt.Errorf("pq: %s has %d coordinates, want %d", data, len(qs), n)
+	}
+	return qs, nil
+}
+
+// marshalqs returns the binary encodings of qs, each one prefixed with its length.
+func marshalqs(qs ...Q) ([]byte, error) {
+	buf := make([]byte, 0)
+	for _, q := range qs {
+		b, err := q.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf = binary.AppendUvarint(buf, uint64(len(b)))
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+// unmarshalqs decodes n numbers encoded by marshalqs.
+func unmarshalqs(data []byte, n int) ([]Q, error) {
+	qs := make([]Q, n)
+	for i := range qs {
+		m, k := binary.Uvarint(data)
+		if k <= 0 || uint64(len(data)-k) < m {
+			return nil, errors.New("pq: invalid binary encoding")
+		}
+		if err := qs[i].UnmarshalBinary(data[k : k+int(m)]); err != nil {
+			return nil, err
+		}
+		data = data[k+int(m):]
+	}
+	if len(data) != 0 {
+		return nil, errors.New("pq: invalid binary encoding")
+	}
+	return qs, nil
+}
@@ -0,0 +1,285 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Mesh files: OFF, OBJ, PLY and STL. A mesh is given by its vertices vs and its faces fs,
+// as computed by ConvHull3q; each face is a polygon given by the indices of its vertices
+// into vs, listed in counter-clockwise order when viewed from outside.
+//
+// The coordinates are read exactly: a decimal number such as 0.1 is converted to Q by ParseQ,
+// and a binary float32 or float64 number is converted to Q by FtoQ.
+//
+// In the text formats (OFF, OBJ, ASCII PLY and ASCII STL), a coordinate is written as an exact
+// decimal number if its decimal expansion terminates (the denominator is 2^a·5^b). Otherwise,
+// if rational is false, it is written as the nearest float64; if rational is true, it is written
+// in the rational extension "n/d", which is read back exactly by this package.
+//
+// In the binary formats (binary PLY and binary STL), a coordinate is written as the nearest
+// float64 in PLY and the nearest float32 in STL. If rational is true and a coordinate is not
+// exactly representable, then an error is returned instead.
+
+var errInexactMesh = fmt.Errorf("%w in the mesh format", ErrInexact)
+
+// meshnum returns x for a text format, see above.
+func meshnum(x Q, rational bool) string {
+	if s, ok := decimal(x); ok {
+		return s
+	}
+	if rational {
+		return x.String()
+	}
+	return strconv.FormatFloat(x.Float64(), 'g', -1, 64)
+}
+
+// meshxyz returns "x y z" for a text format.
+func meshxyz(a Point3q, rational bool) string {
+	return meshnum(a.x, rational) + " " + meshnum(a.y, rational) + " " + meshnum(a.z, rational)
+}
+
+// meshfloat64 returns x as a float64 for a binary format, see above.
+func meshfloat64(x Q, rational bool) (float64, error) {
+	f, exact := r(x).Float64()
+	if !exact && rational {
+		return 0, errInexactMesh
+	}
+	return f, nil
+}
+
+// meshfloat32 returns x as a float32 for a binary format, see above.
+func meshfloat32(x Q, rational bool) (float32, error) {
+	f, exact := r(x).Float32()
+	if !exact && rational {
+		return 0, errInexactMesh
+	}
+	return f, nil
+}
+
+// meshf returns the number f read from a binary format; f must be finite.
+func meshf(f float64) (Q, error) {
+	return FtoQErr(f)
+}
+
+// meshcheck returns an error if a face has fewer than 3 vertices or an index out of range.
+func meshcheck(nv int, fs [][]int) error {
+	for _, f := range fs {
+		if len(f) < 3 {
+			return errors.New("pq: mesh: face with fewer than 3 vertices")
+		}
+		for _, i := range f {
+			if i < 0 || i >= nv {
+				return errors.New("pq: mesh: vertex index out of range")
+			}
+		}
+	}
+	return nil
+}
+
+// meshlines returns the non-empty lines of r split into fields; the text after
+// the comment character comment, if not zero, is removed.
+func meshlines(r io.Reader, comment byte) ([][]string, error) {
+	var lines [][]string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<26)
+	for sc.Scan() {
+		s := sc.Text()
+		if comment != 0 {
+			if i := strings.IndexByte(s, comment); i >= 0 {
+				s = s[:i]
+			}
+		}
+		if f := strings.Fields(s); len(f) > 0 {
+			lines = append(lines, f)
+		}
+	}
+	return lines, sc.Err()
+}
+
+// meshint returns the non-negative integer s.
+func meshint(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, errors.New("pq: mesh: invalid count or index " + strconv.Quote(s))
+	}
+	return n, nil
+}
+
+// WriteOFF writes the mesh (vs,fs) to w in the Object File Format (OFF).
+//
+// See: http://www.geomview.org/docs/html/OFF.html
+func WriteOFF(w io.Writer, vs []Point3q, fs [][]int, rational bool) error {
+	if err := meshcheck(len(vs), fs); err != nil {
+		return err
+	}
+	b := bufio.NewWriter(w)
+	b.WriteString("OFF\n" + strconv.Itoa(len(vs)) + " " + strconv.Itoa(len(fs)) + " 0\n")
+	for _, a := range vs {
+		b.WriteString(meshxyz(a, rational) + "\n")
+	}
+	for _, f := range fs {
+		b.WriteString(strconv.Itoa(len(f)))
+		for _, i := range f {
+			b.WriteString(" " + strconv.Itoa(i))
+		}
+		b.WriteString("\n")
+	}
+	return b.Flush()
+}
+
+// ReadOFF reads a mesh in the Object File Format (OFF) from r. The colors are ignored.
+func ReadOFF(r io.Reader) (vs []Point3q, fs [][]int, err error) {
+	lines, err := meshlines(r, '#')
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(lines) == 0 || lines[0][0] != "OFF" {
+		return nil, nil, errors.New("pq: OFF: missing header")
+	}
+	//
+	// The counts may follow the keyword on the same line.
+	//
+	counts := lines[0][1:]
+	lines = lines[1:]
+	if len(counts) == 0 && len(lines) > 0 {
+		counts, lines = lines[0], lines[1:]
+	}
+	if len(counts) < 2 {
+		return nil, nil, errors.New("pq: OFF: missing counts")
+	}
+	nv, err1 := meshint(counts[0])
+	nf, err2 := meshint(counts[1])
+	if err1 != nil || err2 != nil {
+		return nil, nil, errors.New("pq: OFF: invalid counts")
+	}
+	if nv > len(lines) || nf > len(lines)-nv {
+		return nil, nil, errors.New("pq: OFF: unexpected end of data")
+	}
+	vs = make([]Point3q, nv)
+	for i, l := range lines[:nv] {
+		if len(l) < 3 {
+			return nil, nil, errors.New("pq: OFF: a vertex must have 3 coordinates")
+		}
+		if vs[i], err = meshp(l[:3]); err != nil {
+			return nil, nil, err
+		}
+	}
+	fs = make([][]int, nf)
+	for k, l := range lines[nv : nv+nf] {
+		n, err := meshint(l[0])
+		if err != nil || n > len(l)-1 {
+			return nil, nil, errors.New("pq: OFF: invalid face")
+		}
+		f := make([]int, n)
+		for j := range f {
+			if f[j], err = meshint(l[1+j]); err != nil {
+				return nil, nil, err
+			}
+		}
+		fs[k] = f
+	}
+	if err := meshcheck(nv, fs); err != nil {
+		return nil, nil, err
+	}
+	return vs, fs, nil
+}
+
+// meshp returns the point with the coordinates xyz given as text.
+func meshp(xyz []string) (Point3q, error) {
+	var c [3]Q
+	for k := range c {
+		q, err := ParseQ(xyz[k])
+		if err != nil {
+			return Point3q{}, err
+		}
+		c[k] = q
+	}
+	return Point3q{c[0], c[1], c[2]}, nil
+}
+
+// WriteOBJ writes the mesh (vs,fs) to w in the Wavefront OBJ format.
+//
+// See: http://paulbourke.net/dataformats/obj/
+func WriteOBJ(w io.Writer, vs []Point3q, fs [][]int, rational bool) error {
+	if err := meshcheck(len(vs), fs); err != nil {
+		return err
+	}
+	b := bufio.NewWriter(w)
+	for _, a := range vs {
+		b.WriteString("v " + meshxyz(a, rational) + "\n")
+	}
+	for _, f := range fs {
+		b.WriteString("f")
+		for _, i := range f {
+			b.WriteString(" " + strconv.Itoa(i+1))
+		}
+		b.WriteString("\n")
+	}
+	return b.Flush()
+}
+
+// ReadOBJ reads a mesh in the Wavefront OBJ format from r. Only the vertices "v" and the faces
+// "f" are read; the texture coordinates and the normals of the face vertices are ignored,
+// and so are the other statements. A vertex "v x y z w" with a weight w is divided by w exactly;
+// the vertex colors of "v x y z r g b" are ignored.
+func ReadOBJ(r io.Reader) (vs []Point3q, fs [][]int, err error) {
+	lines, err := meshlines(r, '#')
+	if err != nil {
+		return nil, nil, err
+	}
+	vs, fs = make([]Point3q, 0), make([][]int, 0)
+	for _, l := range lines {
+		switch l[0] {
+		case "v":
+			if len(l) < 4 {
+				return nil, nil, errors.New("pq: OBJ: a vertex must have 3 coordinates")
+			}
+			a, err := meshp(l[1:4])
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(l) == 5 {
+				w, err := ParseQ(l[4])
+				if err != nil {
+					return nil, nil, err
+				}
+				if w.Sgn() == 0 {
+					return nil, nil, errors.New("pq: OBJ: zero vertex weight")
+				}
+				a = Point3q{a.x.Div(w), a.y.Div(w), a.z.Div(w)}
+			}
+			vs = append(vs, a)
+		case "f":
+			f := make([]int, len(l)-1)
+			for j, s := range l[1:] {
+				//
+				// "v", "v/vt", "v//vn" or "v/vt/vn"; a negative index is relative to the end.
+				//
+				if i := strings.IndexByte(s, '/'); i >= 0 {
+					s = s[:i]
+				}
+				i, err := strconv.Atoi(s)
+				if err != nil || i == 0 {
+					return nil, nil, errors.New("pq: OBJ: invalid vertex index " + strconv.Quote(s))
+				}
+				if i < 0 {
+					f[j] = len(vs) + i
+				} else {
+					f[j] = i - 1
+				}
+			}
+			fs = append(fs, f)
+		}
+	}
+	if err := meshcheck(len(vs), fs); err != nil {
+		return nil, nil, err
+	}
+	return vs, fs, nil
+}
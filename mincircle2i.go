@@ -0,0 +1,30 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// MinCircle2i computes the smallest enclosing circle of a collection of points with integer
+// coordinates in the plane. It implements Welzl's randomized algorithm applied to the convex hull
+// of a given collection of points. The circles are represented by the points defining them, so that
+// all the tests are evaluated in integer arithmetic; only the result is converted to Circle2q.
+// The function modifies the input ps by reordering it. If ps is empty, a run-time panic occurs.
+//
+// Reference: E. Welzl, Smallest enclosing disks (balls and ellipsoids),
+// Lecture Notes in Computer Science Volume 555, pp 359-370 (1991).
+//
+// See: http://dx.doi.org/10.1007/BFb0038202
+func MinCircle2i(ps []Point2i) Circle2q {
+	sup := MinCircle2(ps)
+	qs := make([]Point2q, len(sup))
+	for i, p := range sup {
+		qs[i] = p.ToQ()
+	}
+	return suptoCir(qs)
+}
+
+// MinCircle2iErr is like MinCircle2i, but it returns ErrEmpty if ps is empty.
+func MinCircle2iErr(ps []Point2i) (Circle2q, error) {
+	if len(ps) == 0 {
+		return Circle2q{}, ErrEmpty
+	}
+	return MinCircle2i(ps), nil
+}
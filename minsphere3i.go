@@ -0,0 +1,31 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// MinSphere3i computes the smallest enclosing sphere of a collection of points with integer
+// coordinates in the 3-dimensional space. It implements Welzl's randomized algorithm applied to
+// the vertices of the convex hull of a given collection of points. The spheres are represented by
+// the points defining them, so that all the tests are evaluated in integer arithmetic; only the result
+// is converted to Sphere3q. The function modifies the input ps by reordering it. If ps is empty,
+// a run-time panic occurs.
+//
+// Reference: E. Welzl, Smallest enclosing disks (balls and ellipsoids),
+// Lecture Notes in Computer Science Volume 555, pp 359-370 (1991).
+//
+// See: http://dx.doi.org/10.1007/BFb0038202
+func MinSphere3i(ps []Point3i) Sphere3q {
+	sup := MinSphere3(ps)
+	qs := make([]Point3q, len(sup))
+	for i, p := range sup {
+		qs[i] = p.ToQ()
+	}
+	return suptoSph(qs)
+}
+
+// MinSphere3iErr is like MinSphere3i, but it returns ErrEmpty if ps is empty.
+func MinSphere3iErr(ps []Point3i) (Sphere3q, error) {
+	if len(ps) == 0 {
+		return Sphere3q{}, ErrEmpty
+	}
+	return MinSphere3i(ps), nil
+}
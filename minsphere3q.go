@@ -0,0 +1,59 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// MinSphere3q computes the smallest enclosing sphere of a collection of points in the 3-dimensional space.
+// It implements Welzl's randomized algorithm applied to the vertices of the convex hull of a given collection of points.
+// The function modifies the input ps by reordering it. If ps is empty, a run-time panic occurs.
+//
+// Reference: E. Welzl, Smallest enclosing disks (balls and ellipsoids),
+// Lecture Notes in Computer Science Volume 555, pp 359-370 (1991).
+//
+// See: http://dx.doi.org/10.1007/BFb0038202
+func MinSphere3q(ps []Point3q) Sphere3q {
+	return suptoSph(MinSphere3(ps))
+}
+
+// MinSphere3qErr is like MinSphere3q, but it returns ErrEmpty if ps is empty.
+func MinSphere3qErr(ps []Point3q) (Sphere3q, error) {
+	if len(ps) == 0 {
+		return Sphere3q{}, ErrEmpty
+	}
+	return MinSphere3q(ps), nil
+}
+
+// suptoSph returns the sphere defined by the points sup computed by MinSphere3.
+func suptoSph(sup []Point3q) Sphere3q {
+	switch len(sup) {
+	case 1:
+		return PPtoSph(sup[0], sup[0])
+	case 2:
+		return PPtoSph(sup[0], sup[1])
+	case 3:
+		return PPPtoSph(sup[0], sup[1], sup[2])
+	}
+	return PPPPtoSph(sup[0], sup[1], sup[2], sup[3])
+}
+
+// ParMinSphere3q computes the smallest enclosing sphere of a collection of points in the 3-dimensional space.
+// It implements Welzl's randomized algorithm applied to the vertices of the convex hull of a given collection of points.
+// The function modifies the input ps by reordering it. If ps is empty, a run-time panic occurs.
+// If ncpu > 0 then the convex hull computations run in parallel using ncpu goroutines;
+// otherwise the convex hull computations run in parallel using runtime.NumCPU() goroutines.
+//
+// Reference: E. Welzl, Smallest enclosing disks (balls and ellipsoids),
+// Lecture Notes in Computer Science Volume 555, pp 359-370 (1991).
+//
+// See: http://dx.doi.org/10.1007/BFb0038202
+func ParMinSphere3q(ncpu int, ps []Point3q) Sphere3q {
+	vs, _ := ParConvHull3q(ncpu, ps)
+	return suptoSph(minball0(vs))
+}
+
+// ParMinSphere3qErr is like ParMinSphere3q, but it returns ErrEmpty if ps is empty.
+func ParMinSphere3qErr(ncpu int, ps []Point3q) (Sphere3q, error) {
+	if len(ps) == 0 {
+		return Sphere3q{}, ErrEmpty
+	}
+	return ParMinSphere3q(ncpu, ps), nil
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxexpq is the largest absolute value of an exponent accepted by ParseQ.
+const maxexpq = 100000
+
+var errZeroDen = errors.New("zero denominator")
+
+// A NumError records a failed conversion by ParseQ.
+type NumError struct {
+	Num string // the input
+	Err error  // the reason the conversion failed (e.g. strconv.ErrSyntax, strconv.ErrRange)
+}
+
+func (e *NumError) Error() string {
+	return "pq: parsing " + strconv.Quote(e.Num) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the reason the conversion failed.
+func (e *NumError) Unwrap() error {
+	return e.Err
+}
+
+// ParseQ returns the rational number represented by the string s. The conversion is exact.
+// The accepted forms are:
+//
+//	"7/9", "-7/9", "12"     a fraction or an integer in decimal notation
+//	"12.375", "-1e-20"      a decimal number with an optional exponent
+//	"0x1.8p3", "-0x1p-2"    a hexadecimal number with a binary exponent
+//
+// The absolute value of an exponent must not exceed 100000. If s is not valid, then ParseQ
+// returns a *NumError with Err set to strconv.ErrSyntax, strconv.ErrRange (an exponent
+// is too large) or an error describing a zero denominator.
+func ParseQ(s string) (Q, error) {
+	q, err := parseq(s)
+	if err != nil {
+		return Q{}, &NumError{s, err}
+	}
+	return q, nil
+}
+
+func parseq(s string) (Q, error) {
+	if strings.IndexByte(s, '/') >= 0 {
+		return parsefrac(s)
+	}
+	neg := false
+	t := s
+	if t != "" && (t[0] == '+' || t[0] == '-') {
+		neg = t[0] == '-'
+		t = t[1:]
+	}
+	base, expch, digs := 10, "eE", "0123456789"
+	if len(t) > 2 && t[0] == '0' && (t[1] == 'x' || t[1] == 'X') {
+		base, expch, digs = 16, "pP", "0123456789abcdefABCDEF"
+		t = t[2:]
+	}
+	//
+	// The mantissa is int.frac; it is followed by the exponent, which is required for base 16.
+	//
+	mant, exp, hasexp := t, "", false
+	if i := strings.IndexAny(t, expch); i >= 0 {
+		mant, exp, hasexp = t[:i], t[i+1:], true
+	} else if base == 16 {
+		return Q{}, strconv.ErrSyntax
+	}
+	ip, fp := mant, ""
+	if i := strings.IndexByte(mant, '.'); i >= 0 {
+		ip, fp = mant[:i], mant[i+1:]
+	}
+	if ip+fp == "" || strings.Trim(ip+fp, digs) != "" {
+		return Q{}, strconv.ErrSyntax
+	}
+	e := 0
+	if hasexp {
+		ed := exp
+		if ed != "" && (ed[0] == '+' || ed[0] == '-') {
+			ed = ed[1:]
+		}
+		if ed == "" || strings.Trim(ed, "0123456789") != "" {
+			return Q{}, strconv.ErrSyntax
+		}
+		v, err := strconv.Atoi(exp)
+		if err != nil || v > maxexpq || v < -maxexpq {
+			return Q{}, strconv.ErrRange
+		}
+		e = v
+	}
+	//
+	// The value is ipfp·b^(e-len(fp)), where b=10 for base 10, and b=2 and the digits of fp
+	// have 4 bits each for base 16.
+	//
+	n, _ := new(big.Int).SetString(ip+fp, base)
+	r := new(big.Rat).SetInt(n)
+	var pow *big.Int
+	if base == 10 {
+		e -= len(fp)
+		pow = new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(e))), nil)
+	} else {
+		e -= 4 * len(fp)
+		pow = new(big.Int).Lsh(big.NewInt(1), uint(abs(e)))
+	}
+	if e < 0 {
+		r.Quo(r, new(big.Rat).SetInt(pow))
+	} else {
+		r.Mul(r, new(big.Rat).SetInt(pow))
+	}
+	if neg {
+		r.Neg(r)
+	}
+	return rtoq(r), nil
+}
+
+// FormatQ returns x as a decimal number if its decimal expansion terminates (the denominator
+// is 2^a·5^b), e.g. "12.375" or "-0.1"; otherwise it returns x as a fraction "n/d", e.g. "1/3".
+// The result is exact and is accepted by ParseQ.
+func FormatQ(x Q) string {
+	if s, ok := decimal(x); ok {
+		return s
+	}
+	return x.String()
+}
+
+// decimal returns x as a decimal number and true if the denominator of x has no prime factors
+// other than 2 and 5; otherwise it returns false.
+func decimal(x Q) (string, bool) {
+	rx := r(x)
+	num, den := rx.Num(), rx.Denom()
+	if den.IsInt64() && den.Int64() == 1 {
+		return num.String(), true
+	}
+	//
+	// x = num/(2^a·5^b) = num·2^(k-a)·5^(k-b)/10^k, k = max(a,b).
+	//
+	d := new(big.Int).Set(den)
+	a := int(d.TrailingZeroBits())
+	d.Rsh(d, uint(a))
+	b := 0
+	five, m := big.NewInt(5), new(big.Int)
+	for {
+		q, rem := new(big.Int).QuoRem(d, five, m)
+		if rem.Sign() != 0 {
+			break
+		}
+		d, b = q, b+1
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return "", false
+	}
+	k := a
+	if b > k {
+		k = b
+	}
+	n := new(big.Int).Abs(num)
+	n.Lsh(n, uint(k-a))
+	n.Mul(n, new(big.Int).Exp(five, big.NewInt(int64(k-b)), nil))
+	digits := n.String()
+	if len(digits) <= k {
+		digits = strings.Repeat("0", k-len(digits)+1) + digits
+	}
+	s := digits[:len(digits)-k] + "." + digits[len(digits)-k:]
+	if num.Sign() < 0 {
+		s = "-" + s
+	}
+	return s, true
+}
+
+// parsefrac parses a rational number in the form "n/d" or "n", where n and d are decimal integers.
+func parsefrac(s string) (Q, error) {
+	num, den := s, "1"
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		num, den = s[:i], s[i+1:]
+	}
+	n, ok1 := new(big.Int).SetString(num, 10)
+	d, ok2 := new(big.Int).SetString(den, 10)
+	if !ok1 || !ok2 || strings.HasPrefix(den, "+") || strings.HasPrefix(den, "-") {
+		return Q{}, strconv.ErrSyntax
+	}
+	if d.Sign() == 0 {
+		return Q{}, errZeroDen
+	}
+	return rtoq(new(big.Rat).SetFrac(n, d)), nil
+}
+
+// abs returns |n|.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WritePLY writes the mesh (vs,fs) to w in the Polygon File Format (PLY), in ASCII
+// or in binary little-endian. The coordinates are written as properties of type double.
+//
+// See: http://paulbourke.net/dataformats/ply/
+func WritePLY(w io.Writer, vs []Point3q, fs [][]int, bin, rational bool) error {
+	if err := meshcheck(len(vs), fs); err != nil {
+		return err
+	}
+	format, cnt := "ascii", "uchar"
+	if bin {
+		format = "binary_little_endian"
+	}
+	for _, f := range fs {
+		if len(f) > math.MaxUint8 {
+			cnt = "int"
+		}
+	}
+	b := bufio.NewWriter(w)
+	b.WriteString("ply\nformat " + format + " 1.0\n" +
+		"element vertex " + strconv.Itoa(len(vs)) + "\n" +
+		"property double x\nproperty double y\nproperty double z\n" +
+		"element face " + strconv.Itoa(len(fs)) + "\n" +
+		"property list " + cnt + " int vertex_indices\nend_header\n")
+	if !bin {
+		for _, a := range vs {
+			b.WriteString(meshxyz(a, rational) + "\n")
+		}
+		for _, f := range fs {
+			b.WriteString(strconv.Itoa(len(f)))
+			for _, i := range f {
+				b.WriteString(" " + strconv.Itoa(i))
+			}
+			b.WriteString("\n")
+		}
+		return b.Flush()
+	}
+	var buf [8]byte
+	for _, a := range vs {
+		for _, x := range [3]Q{a.x, a.y, a.z} {
+			f, err := meshfloat64(x, rational)
+			if err != nil {
+				return err
+			}
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+			b.Write(buf[:8])
+		}
+	}
+	for _, f := range fs {
+		if cnt == "uchar" {
+			b.WriteByte(byte(len(f)))
+		} else {
+			binary.LittleEndian.PutUint32(buf[:], uint32(len(f)))
+			b.Write(buf[:4])
+		}
+		for _, i := range f {
+			binary.LittleEndian.PutUint32(buf[:], uint32(i))
+			b.Write(buf[:4])
+		}
+	}
+	return b.Flush()
+}
+
+// plyprop is a property of a PLY element; cnt is the type of the count of a list property.
+type plyprop struct {
+	name, typ, cnt string
+}
+
+// plyelem is a PLY element with n instances.
+type plyelem struct {
+	name  string
+	n     int
+	props []plyprop
+}
+
+// plysize is the size in bytes of the PLY types.
+var plysize = map[string]int{
+	"char": 1, "uchar": 1, "short": 2, "ushort": 2, "int": 4, "uint": 4, "float": 4, "double": 8,
+	"int8": 1, "uint8": 1, "int16": 2, "uint16": 2, "int32": 4, "uint32": 4, "float32": 4, "float64": 8,
+}
+
+// ReadPLY reads a mesh in the Polygon File Format (PLY) from r, in ASCII or in binary with either
+// byte order. The vertices are given by the properties x, y and z of the element "vertex";
+// the faces are given by the list property "vertex_indices" or "vertex_index" of the element
+// "face". The other elements and properties are ignored.
+func ReadPLY(r io.Reader) (vs []Point3q, fs [][]int, err error) {
+	br := bufio.NewReader(r)
+	//
+	// Read the header.
+	//
+	var elems []plyelem
+	var order binary.ByteOrder
+	for k := 0; ; k++ {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, nil, errors.New("pq: PLY: unexpected end of header")
+		}
+		f := strings.Fields(line)
+		if k == 0 {
+			if len(f) != 1 || f[0] != "ply" {
+				return nil, nil, errors.New("pq: PLY: missing header")
+			}
+			continue
+		}
+		if len(f) == 0 {
+			continue
+		}
+		switch {
+		case f[0] == "end_header":
+		case f[0] == "format" && len(f) == 3:
+			switch f[1] {
+			case "ascii":
+			case "binary_little_endian":
+				order = binary.LittleEndian
+			case "binary_big_endian":
+				order = binary.BigEndian
+			default:
+				return nil, nil, errors.New("pq: PLY: unknown format " + strconv.Quote(f[1]))
+			}
+		case f[0] == "element" && len(f) == 3:
+			n, err := meshint(f[2])
+			if err != nil {
+				return nil, nil, err
+			}
+			elems = append(elems, plyelem{f[1], n, nil})
+		case f[0] == "property" && len(elems) > 0:
+			var p plyprop
+			if len(f) == 5 && f[1] == "list" {
+				p = plyprop{f[4], f[3], f[2]}
+			} else if len(f) == 3 {
+				p = plyprop{f[2], f[1], ""}
+			} else {
+				return nil, nil, errors.New("pq: PLY: invalid property")
+			}
+			if plysize[p.typ] == 0 || p.cnt != "" && plysize[p.cnt] == 0 {
+				return nil, nil, errors.New("pq: PLY: unknown property type")
+			}
+			e := &elems[len(elems)-1]
+			e.props = append(e.props, p)
+		case f[0] == "comment" || f[0] == "obj_info":
+		default:
+			return nil, nil, errors.New("pq: PLY: invalid header line " + strconv.Quote(strings.TrimSpace(line)))
+		}
+		if f[0] == "end_header" {
+			break
+		}
+	}
+	for _, e := range elems {
+		if e.name != "vertex" {
+			continue
+		}
+		has := map[string]bool{}
+		for _, p := range e.props {
+			has[p.name] = p.cnt == ""
+		}
+		if !has["x"] || !has["y"] || !has["z"] {
+			return nil, nil, errors.New("pq: PLY: missing vertex coordinates")
+		}
+	}
+	//
+	// Read the elements.
+	//
+	var next func(typ string) (Q, error)
+	if order == nil {
+		lines, err := meshlines(br, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		var toks []string
+		for _, l := range lines {
+			toks = append(toks, l...)
+		}
+		next = func(typ string) (Q, error) {
+			if len(toks) == 0 {
+				return Q{}, errors.New("pq: PLY: unexpected end of data")
+			}
+			s := toks[0]
+			toks = toks[1:]
+			return ParseQ(s)
+		}
+	} else {
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		d := bytes.NewReader(data)
+		next = func(typ string) (Q, error) {
+			var buf [8]byte
+			b := buf[:plysize[typ]]
+			if _, err := io.ReadFull(d, b); err != nil {
+				return Q{}, errors.New("pq: PLY: unexpected end of data")
+			}
+			return plyval(typ, b, order)
+		}
+	}
+	vs, fs = make([]Point3q, 0), make([][]int, 0)
+	for _, e := range elems {
+		for i := 0; i < e.n; i++ {
+			var xyz [3]Q
+			var face []int
+			for _, p := range e.props {
+				if p.cnt != "" {
+					c, err := next(p.cnt)
+					if err != nil {
+						return nil, nil, err
+					}
+					n, ok := plyint(c)
+					if !ok {
+						return nil, nil, errors.New("pq: PLY: invalid list count")
+					}
+					list := make([]int, 0)
+					for j := 0; j < n; j++ {
+						v, err := next(p.typ)
+						if err != nil {
+							return nil, nil, err
+						}
+						k, ok := plyint(v)
+						if !ok {
+							return nil, nil, errors.New("pq: PLY: invalid vertex index")
+						}
+						list = append(list, k)
+					}
+					if e.name == "face" && (p.name == "vertex_indices" || p.name == "vertex_index") {
+						face = list
+					}
+					continue
+				}
+				v, err := next(p.typ)
+				if err != nil {
+					return nil, nil, err
+				}
+				if e.name == "vertex" {
+					switch p.name {
+					case "x":
+						xyz[0] = v
+					case "y":
+						xyz[1] = v
+					case "z":
+						xyz[2] = v
+					}
+				}
+			}
+			switch {
+			case e.name == "vertex":
+				vs = append(vs, Point3q{xyz[0], xyz[1], xyz[2]})
+			case face != nil:
+				fs = append(fs, face)
+			}
+		}
+	}
+	if err := meshcheck(len(vs), fs); err != nil {
+		return nil, nil, err
+	}
+	return vs, fs, nil
+}
+
+// plyval returns the binary value b of the PLY type typ.
+func plyval(typ string, b []byte, order binary.ByteOrder) (Q, error) {
+	switch typ {
+	case "char", "int8":
+		return ItoQ(int64(int8(b[0]))), nil
+	case "uchar", "uint8":
+		return ItoQ(int64(b[0])), nil
+	case "short", "int16":
+		return ItoQ(int64(int16(order.Uint16(b)))), nil
+	case "ushort", "uint16":
+		return ItoQ(int64(order.Uint16(b))), nil
+	case "int", "int32":
+		return ItoQ(int64(int32(order.Uint32(b)))), nil
+	case "uint", "uint32":
+		return ItoQ(int64(order.Uint32(b))), nil
+	case "float", "float32":
+		return meshf(float64(math.Float32frombits(order.Uint32(b))))
+	}
+	return meshf(math.Float64frombits(order.Uint64(b)))
+}
+
+// plyint returns q as a non-negative int.
+func plyint(q Q) (int, bool) {
+	if q._r != nil || q.den() != 1 || q.n < 0 || q.n > math.MaxInt32 {
+		return 0, false
+	}
+	return int(q.n), true
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"math"
+	"strconv"
+)
+
+// Point2f represents a point with float64 coordinates in the 2-dimensional Euclidean plane.
+// The predicates are evaluated in float64 arithmetic with an error bound; if the bound does
+// not certify the result, then they are evaluated exactly in rational arithmetic.
+// Hence the predicates are exact, and they are fast unless the points are nearly degenerate.
+type Point2f struct {
+	x, y float64
+}
+
+// XYtoPf returns the point (x,y). If x or y is not finite, a run-time panic occurs.
+func XYtoPf(x, y float64) Point2f {
+	return must(XYtoPfErr(x, y))
+}
+
+// XYtoPfErr is like XYtoPf, but it returns ErrNotFinite if x or y is not finite.
+func XYtoPfErr(x, y float64) (Point2f, error) {
+	if math.IsInf(x, 0) || math.IsNaN(x) || math.IsInf(y, 0) || math.IsNaN(y) {
+		return Point2f{}, ErrNotFinite
+	}
+	return Point2f{x, y}, nil
+}
+
+// X returns the Cartesian x-coordinate of a.
+func (a Point2f) X() float64 {
+	return a.x
+}
+
+// Y returns the Cartesian y-coordinate of a.
+func (a Point2f) Y() float64 {
+	return a.y
+}
+
+// XY returns the Cartesian coordinates of a.
+func (a Point2f) XY() (x, y float64) {
+	return a.x, a.y
+}
+
+// ToQ returns a as a point with rational coordinates.
+func (a Point2f) ToQ() Point2q {
+	return Point2q{FtoQ(a.x), FtoQ(a.y)}
+}
+
+// CmpXY compares the Cartesian coordinates of a and b in xy-order.
+func (a Point2f) CmpXY(b Point2f) int {
+	switch {
+	case a.x < b.x:
+		return -1
+	case a.x > b.x:
+		return +1
+	case a.y < b.y:
+		return -1
+	case a.y > b.y:
+		return +1
+	}
+	return 0
+}
+
+// Orientation returns:
+//
+//	-1 if (a,b,c) are clockwise
+//	 0 if (a,b,c) are collinear
+//	+1 if (a,b,c) are counter-clockwise
+func (a Point2f) Orientation(b, c Point2f) int {
+	if sgn, ok := orient2ff(a.x, a.y, b.x, b.y, c.x, c.y); ok {
+		return sgn
+	}
+	return a.ToQ().Orientation(b.ToQ(), c.ToQ())
+}
+
+// InCircle returns:
+//
+//	-1 if d is outside the circle passing through (a,b,c)
+//	 0 if (a,b,c,d) are cocircular
+//	+1 if d is inside the circle passing through (a,b,c)
+//
+// provided that (a,b,c) are counter-clockwise. If (a,b,c) are clockwise, then the signs are reversed.
+// If (a,b,c) are collinear, then the line through (a,b,c) is regarded as a circle of infinite radius.
+func (a Point2f) InCircle(b, c, d Point2f) int {
+	if sgn, ok := incircle2ff(a, b, c, d); ok {
+		return sgn
+	}
+	return a.ToQ().InCircle(b.ToQ(), c.ToQ(), d.ToQ())
+}
+
+// InDiametral returns:
+//
+//	-1 if c is outside the circle having the segment [a,b] as its diameter
+//	 0 if c is on this circle
+//	+1 if c is inside this circle
+func (a Point2f) InDiametral(b, c Point2f) int {
+	if sgn, ok := dot2ff(a, b, c); ok {
+		return -sgn
+	}
+	return a.ToQ().InDiametral(b.ToQ(), c.ToQ())
+}
+
+// String returns a string representation of a in the form "(x,y)".
+func (a Point2f) String() string {
+	return "(" + strconv.FormatFloat(a.x, 'g', -1, 64) + "," + strconv.FormatFloat(a.y, 'g', -1, 64) + ")"
+}
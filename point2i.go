@@ -0,0 +1,260 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"math/big"
+	"math/bits"
+	"strconv"
+)
+
+// Point2i represents a point with integer coordinates in the 2-dimensional Euclidean plane.
+// The predicates are evaluated in int64 arithmetic with 128-bit intermediate products;
+// big.Int arithmetic is used only if an intermediate result does not fit.
+type Point2i struct {
+	x, y int64
+}
+
+// XYtoPi returns the point (x,y).
+func XYtoPi(x, y int64) Point2i {
+	return Point2i{x, y}
+}
+
+// X returns the Cartesian x-coordinate of a.
+func (a Point2i) X() int64 {
+	return a.x
+}
+
+// Y returns the Cartesian y-coordinate of a.
+func (a Point2i) Y() int64 {
+	return a.y
+}
+
+// XY returns the Cartesian coordinates of a.
+func (a Point2i) XY() (x, y int64) {
+	return a.x, a.y
+}
+
+// ToQ returns a as a point with rational coordinates.
+func (a Point2i) ToQ() Point2q {
+	return Point2q{ItoQ(a.x), ItoQ(a.y)}
+}
+
+// CmpX compares the Cartesian x-coordinates of a and b.
+func (a Point2i) CmpX(b Point2i) int {
+	return cmp64(a.x, b.x)
+}
+
+// CmpY compares the Cartesian y-coordinates of a and b.
+func (a Point2i) CmpY(b Point2i) int {
+	return cmp64(a.y, b.y)
+}
+
+// CmpXY compares the Cartesian coordinates of a and b in xy-order.
+func (a Point2i) CmpXY(b Point2i) int {
+	if cmpx := cmp64(a.x, b.x); cmpx != 0 {
+		return cmpx
+	}
+	return cmp64(a.y, b.y)
+}
+
+// CmpYX compares the Cartesian coordinates of a and b in yx-order.
+func (a Point2i) CmpYX(b Point2i) int {
+	if cmpy := cmp64(a.y, b.y); cmpy != 0 {
+		return cmpy
+	}
+	return cmp64(a.x, b.x)
+}
+
+// Dist2 returns the squared Euclidean distance between a and b.
+func (a Point2i) Dist2(b Point2i) Q {
+	dx, ok1 := sub64(b.x, a.x)
+	dy, ok2 := sub64(b.y, a.y)
+	if ok1 && ok2 {
+		h1, l1 := sqr64(dx)
+		h2, l2 := sqr64(dy)
+		l, c := bits.Add64(l1, l2, 0)
+		h, c := bits.Add64(h1, h2, c)
+		if c == 0 && h == 0 && l < 1<<63 {
+			return ItoQ(int64(l))
+		}
+	}
+	bx, by := bigsub(b.x, a.x), bigsub(b.y, a.y)
+	bx.Mul(bx, bx)
+	by.Mul(by, by)
+	return rtoq(new(big.Rat).SetInt(bx.Add(bx, by)))
+}
+
+// dist2x returns the squared difference of the x-coordinates of a and b.
+func (a Point2i) dist2x(b Point2i) Q {
+	return a.Dist2(Point2i{b.x, a.y})
+}
+
+// dist2y returns the squared difference of the y-coordinates of a and b.
+func (a Point2i) dist2y(b Point2i) Q {
+	return a.Dist2(Point2i{a.x, b.y})
+}
+
+// Orientation returns:
+//
+//	-1 if (a,b,c) are clockwise
+//	 0 if (a,b,c) are collinear
+//	+1 if (a,b,c) are counter-clockwise
+func (a Point2i) Orientation(b, c Point2i) int {
+	//
+	// det = (b.x-a.x)*(c.y-a.y) - (b.y-a.y)*(c.x-a.x).
+	//
+	ux, ok1 := sub64(b.x, a.x)
+	uy, ok2 := sub64(b.y, a.y)
+	vx, ok3 := sub64(c.x, a.x)
+	vy, ok4 := sub64(c.y, a.y)
+	if ok1 && ok2 && ok3 && ok4 {
+		return cmpmul64(ux, vy, uy, vx)
+	}
+	det := new(big.Int).Mul(bigsub(b.x, a.x), bigsub(c.y, a.y))
+	return det.Sub(det, new(big.Int).Mul(bigsub(b.y, a.y), bigsub(c.x, a.x))).Sign()
+}
+
+// InCircle returns:
+//
+//	-1 if d is outside the circle passing through (a,b,c)
+//	 0 if (a,b,c,d) are cocircular
+//	+1 if d is inside the circle passing through (a,b,c)
+//
+// provided that (a,b,c) are counter-clockwise. If (a,b,c) are clockwise, then the signs are reversed.
+// If (a,b,c) are collinear, then the line through (a,b,c) is regarded as a circle of infinite radius.
+func (a Point2i) InCircle(b, c, d Point2i) int {
+	//
+	// The determinant has degree 4 in the coordinate differences; if they are below 2^14,
+	// then it fits in int64.
+	//
+	const small = 1 << 14
+	var ds [6]int64
+	ok := true
+	for i, p := range [3]Point2i{a, b, c} {
+		dx, ok1 := sub64(p.x, d.x)
+		dy, ok2 := sub64(p.y, d.y)
+		ok = ok && ok1 && ok2 && -small < dx && dx < small && -small < dy && dy < small
+		ds[2*i], ds[2*i+1] = dx, dy
+	}
+	if ok {
+		adx, ady, bdx, bdy, cdx, cdy := ds[0], ds[1], ds[2], ds[3], ds[4], ds[5]
+		ad2 := adx*adx + ady*ady
+		bd2 := bdx*bdx + bdy*bdy
+		cd2 := cdx*cdx + cdy*cdy
+		det := adx*(bdy*cd2-cdy*bd2) - ady*(bdx*cd2-cdx*bd2) + ad2*(bdx*cdy-cdx*bdy)
+		return cmp64(det, 0)
+	}
+	var bs [3][3]*big.Int
+	for i, p := range [3]Point2i{a, b, c} {
+		dx, dy := bigsub(p.x, d.x), bigsub(p.y, d.y)
+		d2 := new(big.Int).Mul(dx, dx)
+		d2.Add(d2, new(big.Int).Mul(dy, dy))
+		bs[i] = [3]*big.Int{dx, dy, d2}
+	}
+	return bigdet3x3(bs).Sign()
+}
+
+// InDiametral returns:
+//
+//	-1 if c is outside the circle having the segment [a,b] as its diameter
+//	 0 if c is on this circle
+//	+1 if c is inside this circle
+func (a Point2i) InDiametral(b, c Point2i) int {
+	//
+	// The sign of -(a-c)·(b-c).
+	//
+	ux, ok1 := sub64(a.x, c.x)
+	uy, ok2 := sub64(a.y, c.y)
+	vx, ok3 := sub64(b.x, c.x)
+	vy, ok4 := sub64(b.y, c.y)
+	if ok1 && ok2 && ok3 && ok4 && uy != -1<<63 {
+		return cmpmul64(-uy, vy, ux, vx)
+	}
+	dot := new(big.Int).Mul(bigsub(a.x, c.x), bigsub(b.x, c.x))
+	dot.Add(dot, new(big.Int).Mul(bigsub(a.y, c.y), bigsub(b.y, c.y)))
+	return -dot.Sign()
+}
+
+// String returns a string representation of a in the form "(x,y)".
+func (a Point2i) String() string {
+	return "(" + strconv.FormatInt(a.x, 10) + "," + strconv.FormatInt(a.y, 10) + ")"
+}
+
+// cmp64 compares a and b.
+func cmp64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return +1
+	}
+	return 0
+}
+
+// sub64 returns a-b and ok=false if the difference overflows int64.
+func sub64(a, b int64) (int64, bool) {
+	c := a - b
+	if (c < a) != (b > 0) {
+		return 0, false
+	}
+	return c, true
+}
+
+// sqr64 returns the 128-bit square of a.
+func sqr64(a int64) (hi, lo uint64) {
+	u := uint64(a)
+	if a < 0 {
+		u = -u
+	}
+	return bits.Mul64(u, u)
+}
+
+// cmpmul64 compares the 128-bit products a*b and c*d.
+func cmpmul64(a, b, c, d int64) int {
+	sign := func(a, b int64) int {
+		if a == 0 || b == 0 {
+			return 0
+		}
+		if (a < 0) != (b < 0) {
+			return -1
+		}
+		return +1
+	}
+	abs := func(a int64) uint64 {
+		if a < 0 {
+			return -uint64(a)
+		}
+		return uint64(a)
+	}
+	s1, s2 := sign(a, b), sign(c, d)
+	if s1 != s2 || s1 == 0 {
+		return cmp64(int64(s1), int64(s2))
+	}
+	h1, l1 := bits.Mul64(abs(a), abs(b))
+	h2, l2 := bits.Mul64(abs(c), abs(d))
+	r := 0
+	switch {
+	case h1 < h2 || (h1 == h2 && l1 < l2):
+		r = -1
+	case h1 > h2 || (h1 == h2 && l1 > l2):
+		r = +1
+	}
+	return r * s1
+}
+
+// bigsub returns a-b as a big.Int.
+func bigsub(a, b int64) *big.Int {
+	return new(big.Int).Sub(big.NewInt(a), big.NewInt(b))
+}
+
+// bigdet3x3 computes the determinant of a 3-by-3 matrix of big.Int numbers.
+func bigdet3x3(m [3][3]*big.Int) *big.Int {
+	minor := func(i, j, k, l int) *big.Int {
+		p := new(big.Int).Mul(m[i][k], m[j][l])
+		return p.Sub(p, new(big.Int).Mul(m[j][k], m[i][l]))
+	}
+	det := new(big.Int).Mul(m[0][2], minor(1, 2, 0, 1))
+	det.Sub(det, new(big.Int).Mul(m[1][2], minor(0, 2, 0, 1)))
+	return det.Add(det, new(big.Int).Mul(m[2][2], minor(0, 1, 0, 1)))
+}
@@ -60,6 +60,18 @@ func (a Point2q) Dist2(b Point2q) Q {
 	return (dx.Mul(dx)).Add(dy.Mul(dy))
 }
 
+// dist2x returns the squared difference of the x-coordinates of a and b.
+func (a Point2q) dist2x(b Point2q) Q {
+	dx := a.x.Sub(b.x)
+	return dx.Mul(dx)
+}
+
+// dist2y returns the squared difference of the y-coordinates of a and b.
+func (a Point2q) dist2y(b Point2q) Q {
+	dy := a.y.Sub(b.y)
+	return dy.Mul(dy)
+}
+
 // Add returns the point obtained by translating a by u.
 func (a Point2q) Add(u Vector2q) Point2q {
 	return Point2q{a.x.Add(u.x), a.y.Add(u.y)}
@@ -76,10 +88,47 @@ func (a Point2q) Sub(u Vector2q) Point2q {
 //	 0 if (a,b,c) are collinear
 //	+1 if (a,b,c) are counter-clockwise
 func (a Point2q) Orientation(b, c Point2q) int {
+	if sgn, ok := orient2f(a, b, c); ok {
+		return sgn
+	}
 	det := Det2x2(b.x.Sub(a.x), b.y.Sub(a.y), c.x.Sub(a.x), c.y.Sub(a.y))
 	return det.Sgn()
 }
 
+// InCircle returns:
+//
+//	-1 if d is outside the circle passing through (a,b,c)
+//	 0 if (a,b,c,d) are cocircular
+//	+1 if d is inside the circle passing through (a,b,c)
+//
+// provided that (a,b,c) are counter-clockwise. If (a,b,c) are clockwise, then the signs are reversed.
+// If (a,b,c) are collinear, then the line through (a,b,c) is regarded as a circle of infinite radius.
+func (a Point2q) InCircle(b, c, d Point2q) int {
+	if sgn, ok := incircle2f(a, b, c, d); ok {
+		return sgn
+	}
+	adx, ady := a.x.Sub(d.x), a.y.Sub(d.y)
+	bdx, bdy := b.x.Sub(d.x), b.y.Sub(d.y)
+	cdx, cdy := c.x.Sub(d.x), c.y.Sub(d.y)
+	ad2 := (adx.Mul(adx)).Add(ady.Mul(ady))
+	bd2 := (bdx.Mul(bdx)).Add(bdy.Mul(bdy))
+	cd2 := (cdx.Mul(cdx)).Add(cdy.Mul(cdy))
+	det := Det3x3(adx, ady, ad2, bdx, bdy, bd2, cdx, cdy, cd2)
+	return det.Sgn()
+}
+
+// InDiametral returns:
+//
+//	-1 if c is outside the circle having the segment [a,b] as its diameter
+//	 0 if c is on this circle
+//	+1 if c is inside this circle
+func (a Point2q) InDiametral(b, c Point2q) int {
+	if sgn, ok := indiametral2f(a, b, c); ok {
+		return sgn
+	}
+	return -c.Vector(a).Dot(c.Vector(b)).Sgn()
+}
+
 // Midpoint returns the middle of the segment [a,b].
 func (a Point2q) Midpoint(b Point2q) Point2q {
 	x := (a.x.Add(b.x)).Div(qtwo)
@@ -0,0 +1,285 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"math/big"
+	"math/bits"
+	"strconv"
+)
+
+// Point3i represents a point with integer coordinates in the 3-dimensional Euclidean space.
+// The predicates are evaluated in int64 arithmetic if the coordinate differences are small;
+// otherwise big.Int arithmetic is used.
+type Point3i struct {
+	x, y, z int64
+}
+
+// XYZtoPi returns the point (x,y,z).
+func XYZtoPi(x, y, z int64) Point3i {
+	return Point3i{x, y, z}
+}
+
+// X returns the Cartesian x-coordinate of a.
+func (a Point3i) X() int64 {
+	return a.x
+}
+
+// Y returns the Cartesian y-coordinate of a.
+func (a Point3i) Y() int64 {
+	return a.y
+}
+
+// Z returns the Cartesian z-coordinate of a.
+func (a Point3i) Z() int64 {
+	return a.z
+}
+
+// XYZ returns the Cartesian coordinates of a.
+func (a Point3i) XYZ() (x, y, z int64) {
+	return a.x, a.y, a.z
+}
+
+// ToQ returns a as a point with rational coordinates.
+func (a Point3i) ToQ() Point3q {
+	return Point3q{ItoQ(a.x), ItoQ(a.y), ItoQ(a.z)}
+}
+
+// CmpX compares the Cartesian x-coordinates of a and b.
+func (a Point3i) CmpX(b Point3i) int {
+	return cmp64(a.x, b.x)
+}
+
+// CmpY compares the Cartesian y-coordinates of a and b.
+func (a Point3i) CmpY(b Point3i) int {
+	return cmp64(a.y, b.y)
+}
+
+// CmpZ compares the Cartesian z-coordinates of a and b.
+func (a Point3i) CmpZ(b Point3i) int {
+	return cmp64(a.z, b.z)
+}
+
+// CmpXYZ compares the Cartesian coordinates of a and b in xyz-order.
+func (a Point3i) CmpXYZ(b Point3i) int {
+	if cmpx := cmp64(a.x, b.x); cmpx != 0 {
+		return cmpx
+	}
+	if cmpy := cmp64(a.y, b.y); cmpy != 0 {
+		return cmpy
+	}
+	return cmp64(a.z, b.z)
+}
+
+// Dist2 returns the squared Euclidean distance between a and b.
+func (a Point3i) Dist2(b Point3i) Q {
+	dx, ok1 := sub64(b.x, a.x)
+	dy, ok2 := sub64(b.y, a.y)
+	dz, ok3 := sub64(b.z, a.z)
+	if ok1 && ok2 && ok3 {
+		h1, l1 := sqr64(dx)
+		h2, l2 := sqr64(dy)
+		h3, l3 := sqr64(dz)
+		l, c := bits.Add64(l1, l2, 0)
+		h, c1 := bits.Add64(h1, h2, c)
+		l, c = bits.Add64(l, l3, 0)
+		h, c2 := bits.Add64(h, h3, c)
+		if c1 == 0 && c2 == 0 && h == 0 && l < 1<<63 {
+			return ItoQ(int64(l))
+		}
+	}
+	sum := new(big.Int)
+	for _, d := range [3]*big.Int{bigsub(b.x, a.x), bigsub(b.y, a.y), bigsub(b.z, a.z)} {
+		sum.Add(sum, d.Mul(d, d))
+	}
+	return rtoq(new(big.Rat).SetInt(sum))
+}
+
+// Orientation returns the sign of the determinant det[b-a;c-a;d-a], i.e.:
+//
+//	-1 if d is below the plane through (a,b,c)
+//	 0 if (a,b,c,d) are coplanar
+//	+1 if d is above the plane through (a,b,c)
+//
+// where "above" is the side from which (a,b,c) appear counter-clockwise.
+func (a Point3i) Orientation(b, c, d Point3i) int {
+	//
+	// The determinant has degree 3 in the coordinate differences; if they are below 2^19,
+	// then it fits in int64.
+	//
+	const small = 1 << 19
+	var m [3][3]int64
+	ok := true
+	for i, p := range [3]Point3i{b, c, d} {
+		for j, dd := range [3][2]int64{{p.x, a.x}, {p.y, a.y}, {p.z, a.z}} {
+			v, ok1 := sub64(dd[0], dd[1])
+			ok = ok && ok1 && -small < v && v < small
+			m[i][j] = v
+		}
+	}
+	if ok {
+		det := m[0][0]*(m[1][1]*m[2][2]-m[2][1]*m[1][2]) -
+			m[1][0]*(m[0][1]*m[2][2]-m[2][1]*m[0][2]) +
+			m[2][0]*(m[0][1]*m[1][2]-m[1][1]*m[0][2])
+		return cmp64(det, 0)
+	}
+	var bs [3][3]*big.Int
+	for i, p := range [3]Point3i{b, c, d} {
+		bs[i] = [3]*big.Int{bigsub(p.x, a.x), bigsub(p.y, a.y), bigsub(p.z, a.z)}
+	}
+	return bigdet3x3(bs).Sign()
+}
+
+// InSphere returns:
+//
+//	-1 if e is outside the sphere passing through (a,b,c,d)
+//	 0 if (a,b,c,d,e) are cospherical
+//	+1 if e is inside the sphere passing through (a,b,c,d)
+//
+// provided that a.Orientation(b,c,d) > 0. If a.Orientation(b,c,d) < 0, then the signs are reversed.
+func (a Point3i) InSphere(b, c, d, e Point3i) int {
+	var bs [4][4]*big.Int
+	for i, p := range [4]Point3i{a, b, c, d} {
+		dx, dy, dz := bigsub(p.x, e.x), bigsub(p.y, e.y), bigsub(p.z, e.z)
+		d2 := new(big.Int).Mul(dx, dx)
+		d2.Add(d2, new(big.Int).Mul(dy, dy))
+		d2.Add(d2, new(big.Int).Mul(dz, dz))
+		bs[i] = [4]*big.Int{dx, dy, dz, d2}
+	}
+	//
+	// Expand along the last column.
+	//
+	det := new(big.Int)
+	for i := range bs {
+		var m [3][3]*big.Int
+		for j, k := 0, 0; j < 4; j++ {
+			if j != i {
+				m[k] = [3]*big.Int{bs[j][0], bs[j][1], bs[j][2]}
+				k++
+			}
+		}
+		t := new(big.Int).Mul(bs[i][3], bigdet3x3(m))
+		if i%2 == 0 {
+			det.Sub(det, t)
+		} else {
+			det.Add(det, t)
+		}
+	}
+	return -det.Sign()
+}
+
+// CoplanarOrientation returns the orientation of (a,b,c) in their plane:
+//
+//	 0 if (a,b,c) are collinear
+//	±1 otherwise
+//
+// The sign is the orientation of the projection of (a,b,c) onto the first of the coordinate planes
+// xy, yz and zx on which it is not degenerate, as in Point3q.CoplanarOrientation.
+func (a Point3i) CoplanarOrientation(b, c Point3i) int {
+	if o := (Point2i{a.x, a.y}).Orientation(Point2i{b.x, b.y}, Point2i{c.x, c.y}); o != 0 {
+		return o
+	}
+	if o := (Point2i{a.y, a.z}).Orientation(Point2i{b.y, b.z}, Point2i{c.y, c.z}); o != 0 {
+		return o
+	}
+	return (Point2i{a.z, a.x}).Orientation(Point2i{b.z, b.x}, Point2i{c.z, c.x})
+}
+
+// InEquatorial returns:
+//
+//	-1 if d is outside the smallest sphere passing through (a,b,c)
+//	 0 if d is on this sphere
+//	+1 if d is inside this sphere
+//
+// The smallest sphere passing through (a,b,c) has the circle through (a,b,c) as its equator.
+// If (a,b,c) are collinear and distinct, a run-time panic occurs.
+func (a Point3i) InEquatorial(b, c, d Point3i) int {
+	u := [3]*big.Int{bigsub(b.x, a.x), bigsub(b.y, a.y), bigsub(b.z, a.z)}
+	v := [3]*big.Int{bigsub(c.x, a.x), bigsub(c.y, a.y), bigsub(c.z, a.z)}
+	e := [3]*big.Int{bigsub(d.x, a.x), bigsub(d.y, a.y), bigsub(d.z, a.z)}
+	uv := bigcrs(u, v)
+	if uv[0].Sign() == 0 && uv[1].Sign() == 0 && uv[2].Sign() == 0 {
+		switch {
+		case a.CmpXYZ(b) == 0:
+			return b.InDiametral(c, d)
+		case b.CmpXYZ(c) == 0, c.CmpXYZ(a) == 0:
+			return a.InDiametral(b, d)
+		}
+		panic(ErrCollinear)
+	}
+	//
+	// As in Point3q.InEquatorial: d is inside if and only if (d-a)·w > |u×v|²|d-a|²,
+	// where w=(|u|²v-|v|²u)×(u×v).
+	//
+	u2, v2 := bigdot(u, u), bigdot(v, v)
+	var t [3]*big.Int
+	for i := range t {
+		t[i] = new(big.Int).Mul(u2, v[i])
+		t[i].Sub(t[i], new(big.Int).Mul(v2, u[i]))
+	}
+	lhs := bigdot(e, bigcrs(t, uv))
+	rhs := new(big.Int).Mul(bigdot(uv, uv), bigdot(e, e))
+	return lhs.Cmp(rhs)
+}
+
+// InDiametral returns:
+//
+//	-1 if c is outside the sphere having the segment [a,b] as its diameter
+//	 0 if c is on this sphere
+//	+1 if c is inside this sphere
+func (a Point3i) InDiametral(b, c Point3i) int {
+	//
+	// The sign of -(a-c)·(b-c); if the differences are below 2^30, then it fits in int64.
+	//
+	const small = 1 << 30
+	var ds [6]int64
+	ok := true
+	for i, dd := range [6][2]int64{{a.x, c.x}, {a.y, c.y}, {a.z, c.z}, {b.x, c.x}, {b.y, c.y}, {b.z, c.z}} {
+		v, ok1 := sub64(dd[0], dd[1])
+		ok = ok && ok1 && -small < v && v < small
+		ds[i] = v
+	}
+	if ok {
+		return -cmp64(ds[0]*ds[3]+ds[1]*ds[4]+ds[2]*ds[5], 0)
+	}
+	u := [3]*big.Int{bigsub(a.x, c.x), bigsub(a.y, c.y), bigsub(a.z, c.z)}
+	v := [3]*big.Int{bigsub(b.x, c.x), bigsub(b.y, c.y), bigsub(b.z, c.z)}
+	return -bigdot(u, v).Sign()
+}
+
+// cell returns the cell of side 2^e containing a, i.e., the integer parts of the coordinates
+// of a divided by 2^e.
+func (a Point3i) cell(e int) [3]*big.Int {
+	floor := func(x int64) *big.Int {
+		switch {
+		case e < 0:
+			return new(big.Int).Lsh(big.NewInt(x), uint(-e))
+		case e > 63:
+			return big.NewInt(x >> 63)
+		}
+		return big.NewInt(x >> uint(e))
+	}
+	return [3]*big.Int{floor(a.x), floor(a.y), floor(a.z)}
+}
+
+// String returns a string representation of a in the form "(x,y,z)".
+func (a Point3i) String() string {
+	return "(" + strconv.FormatInt(a.x, 10) + "," + strconv.FormatInt(a.y, 10) + "," + strconv.FormatInt(a.z, 10) + ")"
+}
+
+// bigdot returns the dot product u·v.
+func bigdot(u, v [3]*big.Int) *big.Int {
+	d := new(big.Int).Mul(u[0], v[0])
+	d.Add(d, new(big.Int).Mul(u[1], v[1]))
+	return d.Add(d, new(big.Int).Mul(u[2], v[2]))
+}
+
+// bigcrs returns the cross product u×v.
+func bigcrs(u, v [3]*big.Int) (w [3]*big.Int) {
+	for i := range w {
+		j, k := (i+1)%3, (i+2)%3
+		w[i] = new(big.Int).Mul(u[j], v[k])
+		w[i].Sub(w[i], new(big.Int).Mul(u[k], v[j]))
+	}
+	return w
+}
@@ -102,6 +102,109 @@ func (a Point3q) Sub(u Vector3q) Point3q {
 	return Point3q{a.x.Sub(u.x), a.y.Sub(u.y), a.z.Sub(u.z)}
 }
 
+// Orientation returns:
+//
+//	-1 if (a,b,c) are clockwise when viewed from d
+//	 0 if (a,b,c,d) are coplanar
+//	+1 if (a,b,c) are counter-clockwise when viewed from d
+func (a Point3q) Orientation(b, c, d Point3q) int {
+	det := Det3x3(b.x.Sub(a.x), b.y.Sub(a.y), b.z.Sub(a.z),
+		c.x.Sub(a.x), c.y.Sub(a.y), c.z.Sub(a.z),
+		d.x.Sub(a.x), d.y.Sub(a.y), d.z.Sub(a.z))
+	return det.Sgn()
+}
+
+// CoplanarOrientation returns the orientation of (a,b,c) in their plane:
+//
+//	 0 if (a,b,c) are collinear
+//	±1 otherwise
+//
+// The sign is the orientation of the projection of (a,b,c) onto the first of the coordinate planes
+// xy, yz and zx on which it is not degenerate, so that it is consistent for all triples of points
+// in a plane, i.e., it is a 2-dimensional orientation in this plane.
+func (a Point3q) CoplanarOrientation(b, c Point3q) int {
+	if o := (Point2q{a.x, a.y}).Orientation(Point2q{b.x, b.y}, Point2q{c.x, c.y}); o != 0 {
+		return o
+	}
+	if o := (Point2q{a.y, a.z}).Orientation(Point2q{b.y, b.z}, Point2q{c.y, c.z}); o != 0 {
+		return o
+	}
+	return (Point2q{a.z, a.x}).Orientation(Point2q{b.z, b.x}, Point2q{c.z, c.x})
+}
+
+// InSphere returns:
+//
+//	-1 if e is outside the sphere passing through (a,b,c,d)
+//	 0 if (a,b,c,d,e) are cospherical
+//	+1 if e is inside the sphere passing through (a,b,c,d)
+//
+// provided that a.Orientation(b,c,d) > 0. If a.Orientation(b,c,d) < 0, then the signs are reversed.
+func (a Point3q) InSphere(b, c, d, e Point3q) int {
+	var m [4][4]Q
+	for i, p := range [4]Point3q{a, b, c, d} {
+		dx, dy, dz := p.x.Sub(e.x), p.y.Sub(e.y), p.z.Sub(e.z)
+		m[i] = [4]Q{dx, dy, dz, (dx.Mul(dx)).Add(dy.Mul(dy)).Add(dz.Mul(dz))}
+	}
+	//
+	// Expand along the last column.
+	//
+	det := qzer
+	for i := range m {
+		var r [3][3]Q
+		for j, k := 0, 0; j < 4; j++ {
+			if j != i {
+				r[k] = [3]Q{m[j][0], m[j][1], m[j][2]}
+				k++
+			}
+		}
+		t := m[i][3].Mul(Det3x3(r[0][0], r[0][1], r[0][2], r[1][0], r[1][1], r[1][2], r[2][0], r[2][1], r[2][2]))
+		if i%2 == 0 {
+			det = det.Sub(t)
+		} else {
+			det = det.Add(t)
+		}
+	}
+	return -det.Sgn()
+}
+
+// InEquatorial returns:
+//
+//	-1 if d is outside the smallest sphere passing through (a,b,c)
+//	 0 if d is on this sphere
+//	+1 if d is inside this sphere
+//
+// The smallest sphere passing through (a,b,c) has the circle through (a,b,c) as its equator.
+// If (a,b,c) are collinear and distinct, a run-time panic occurs.
+func (a Point3q) InEquatorial(b, c, d Point3q) int {
+	u, v := a.Vector(b), a.Vector(c)
+	uv := u.Crs(v)
+	if uv.MaxAbs().Sgn() == 0 {
+		switch {
+		case a.CmpXYZ(b) == 0:
+			return b.InDiametral(c, d)
+		case b.CmpXYZ(c) == 0, c.CmpXYZ(a) == 0:
+			return a.InDiametral(b, d)
+		}
+		panic(ErrCollinear)
+	}
+	//
+	// The circumcenter is a+w/(2|u×v|²), w=(|u|²v-|v|²u)×(u×v), so that d is inside
+	// if and only if (d-a)·w > |u×v|²|d-a|².
+	//
+	w := (v.Mul(u.Abs2())).Sub(u.Mul(v.Abs2())).Crs(uv)
+	e := a.Vector(d)
+	return e.Dot(w).Cmp(uv.Abs2().Mul(e.Abs2()))
+}
+
+// InDiametral returns:
+//
+//	-1 if c is outside the sphere having the segment [a,b] as its diameter
+//	 0 if c is on this sphere
+//	+1 if c is inside this sphere
+func (a Point3q) InDiametral(b, c Point3q) int {
+	return -c.Vector(a).Dot(c.Vector(b)).Sgn()
+}
+
 // Midpoint returns the middle of the segment [a,b].
 func (a Point3q) Midpoint(b Point3q) Point3q {
 	x := (a.x.Add(b.x)).Div(qtwo)
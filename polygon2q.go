@@ -0,0 +1,291 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// Polygon2q represents a polygon in the 2-dimensional Euclidean plane given by a closed ring
+// of vertices. The last vertex is implicitly connected to the first one.
+type Polygon2q struct {
+	vs []Point2q
+}
+
+// PtoPoly returns the polygon with the vertices ps. The slice ps is copied.
+func PtoPoly(ps []Point2q) Polygon2q {
+	return Polygon2q{append([]Point2q{}, ps...)}
+}
+
+// HulltoPoly returns the convex polygon with the vertices of the lower hull and the upper hull
+// computed by ConvHull2q. The vertices are listed in counter-clockwise order.
+// The hull of a single point is the polygon with one vertex.
+func HulltoPoly(lower, upper []Point2q) Polygon2q {
+	if len(lower) == 1 && len(upper) == 1 && lower[0].CmpXY(upper[0]) == 0 {
+		return Polygon2q{[]Point2q{lower[0]}}
+	}
+	if len(lower) > 1 {
+		lower = lower[:len(lower)-1]
+	}
+	if len(upper) > 1 {
+		upper = upper[:len(upper)-1]
+	}
+	vs := make([]Point2q, 0, len(lower)+len(upper))
+	vs = append(vs, lower...)
+	vs = append(vs, upper...)
+	return Polygon2q{vs}
+}
+
+// Len returns the number of vertices of p.
+func (p Polygon2q) Len() int {
+	return len(p.vs)
+}
+
+// Vertex returns the i-th vertex of p.
+func (p Polygon2q) Vertex(i int) Point2q {
+	return p.vs[i]
+}
+
+// Vertices returns a copy of the vertices of p.
+func (p Polygon2q) Vertices() []Point2q {
+	return append([]Point2q{}, p.vs...)
+}
+
+// Edge returns the i-th edge of p, i.e., the segment from the i-th vertex to the next one.
+func (p Polygon2q) Edge(i int) Segment2q {
+	return Segment2q{p.vs[i], p.vs[(i+1)%len(p.vs)]}
+}
+
+// Reverse returns p with the vertices listed in the reverse order.
+func (p Polygon2q) Reverse() Polygon2q {
+	n := len(p.vs)
+	vs := make([]Point2q, n)
+	for i := range vs {
+		vs[i] = p.vs[n-1-i]
+	}
+	return Polygon2q{vs}
+}
+
+// Area returns the signed area of p. The area is positive if p is counter-clockwise.
+func (p Polygon2q) Area() Q {
+	return p.cross().Div(qtwo)
+}
+
+// cross returns twice the signed area of p. The vertices are translated by -p.vs[0].
+func (p Polygon2q) cross() Q {
+	sum := qzer
+	n := len(p.vs)
+	for i := 1; i+1 < n; i++ {
+		u, v := p.vs[0].Vector(p.vs[i]), p.vs[0].Vector(p.vs[i+1])
+		sum = sum.Add(Det2x2(u.x, u.y, v.x, v.y))
+	}
+	return sum
+}
+
+// Centroid returns the centroid (center of mass) of the region bounded by p.
+// If the area of p is zero, a run-time panic occurs.
+func (p Polygon2q) Centroid() Point2q {
+	sum := p.cross()
+	if sum.Sgn() == 0 {
+		panic(ErrZeroArea)
+	}
+	//
+	// C = v0 + Σ(dᵢ+dᵢ₊₁)·det(dᵢ,dᵢ₊₁)/(3·Σdet(dᵢ,dᵢ₊₁)), dᵢ = vᵢ-v0.
+	//
+	cx, cy := qzer, qzer
+	n := len(p.vs)
+	for i := 1; i+1 < n; i++ {
+		u, v := p.vs[0].Vector(p.vs[i]), p.vs[0].Vector(p.vs[i+1])
+		det := Det2x2(u.x, u.y, v.x, v.y)
+		cx = cx.Add((u.x.Add(v.x)).Mul(det))
+		cy = cy.Add((u.y.Add(v.y)).Mul(det))
+	}
+	den := sum.Mul(ItoQ(3))
+	return p.vs[0].Add(Vector2q{cx.Div(den), cy.Div(den)})
+}
+
+// CentroidErr is like Centroid, but it returns ErrZeroArea if the area of p is zero.
+func (p Polygon2q) CentroidErr() (Point2q, error) {
+	if p.cross().Sgn() == 0 {
+		return Point2q{}, ErrZeroArea
+	}
+	return p.Centroid(), nil
+}
+
+// Orientation returns:
+//
+//	-1 if p is clockwise (negative area)
+//	 0 if p has zero area
+//	+1 if p is counter-clockwise (positive area)
+func (p Polygon2q) Orientation() int {
+	return p.cross().Sgn()
+}
+
+// Convex reports whether p is a convex polygon, i.e., a simple polygon with nonzero area
+// whose vertices all turn in the same direction. Collinear consecutive vertices are allowed,
+// but repeated vertices and reversals are not.
+func (p Polygon2q) Convex() bool {
+	n := len(p.vs)
+	if n < 3 {
+		return false
+	}
+	turn := 0
+	xsgn, ysgn := 0, 0
+	xfst, yfst := 0, 0
+	xchg, ychg := 0, 0
+	for i := 0; i < n; i++ {
+		a, b, c := p.vs[i], p.vs[(i+1)%n], p.vs[(i+2)%n]
+		u, v := a.Vector(b), b.Vector(c)
+		if u.MaxAbs().Sgn() == 0 {
+			return false
+		}
+		switch o := a.Orientation(b, c); {
+		case o == 0:
+			if u.Dot(v).Sgn() <= 0 {
+				return false
+			}
+		case turn == 0:
+			turn = o
+		case turn != o:
+			return false
+		}
+		//
+		// The edge directions of a convex polygon change sign exactly twice in each coordinate.
+		//
+		if s := u.x.Sgn(); s != 0 {
+			if xsgn != 0 && s != xsgn {
+				xchg++
+			}
+			if xfst == 0 {
+				xfst = s
+			}
+			xsgn = s
+		}
+		if s := u.y.Sgn(); s != 0 {
+			if ysgn != 0 && s != ysgn {
+				ychg++
+			}
+			if yfst == 0 {
+				yfst = s
+			}
+			ysgn = s
+		}
+	}
+	if turn == 0 {
+		return false
+	}
+	//
+	// Count the change between the last and the first edge.
+	//
+	if xfst != xsgn {
+		xchg++
+	}
+	if yfst != ysgn {
+		ychg++
+	}
+	return xchg <= 2 && ychg <= 2
+}
+
+// Simple reports whether p is a simple polygon, i.e., it has at least 3 vertices and
+// its edges intersect only at the common vertices of consecutive edges.
+func (p Polygon2q) Simple() bool {
+	n := len(p.vs)
+	if n < 3 {
+		return false
+	}
+	ss := make([]Segment2q, n)
+	for i := range ss {
+		ss[i] = p.Edge(i)
+	}
+	for _, c := range Crossings2q(ss) {
+		segs := c.Segments()
+		if len(segs) != 2 {
+			return false
+		}
+		i, j := segs[0], segs[1]
+		switch {
+		case j == i+1:
+			if c.Point().CmpXY(p.vs[j]) != 0 {
+				return false
+			}
+		case i == 0 && j == n-1:
+			if c.Point().CmpXY(p.vs[0]) != 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Side returns:
+//
+//	-1 if a is outside p
+//	 0 if a is on the boundary of p
+//	+1 if a is inside p
+//
+// The polygon p must be simple; it may be clockwise or counter-clockwise.
+func (p Polygon2q) Side(a Point2q) int {
+	side, _, _ := p.Locate(a)
+	return side
+}
+
+// Locate returns the position of a with respect to p. The value of side is the same as p.Side(a).
+// If a is on the boundary of p, then either a is the vertex p.Vertex(vertex) and edge=-1, or
+// a is in the interior of the edge p.Edge(edge) and vertex=-1; otherwise vertex=edge=-1.
+// It implements the winding number test with exact orientations.
+// The polygon p must be simple; it may be clockwise or counter-clockwise.
+//
+// Reference: D. Sunday, Inclusion of a point in a polygon (2001).
+//
+// See: http://geomalgorithms.com/a03-_inclusion.html
+func (p Polygon2q) Locate(a Point2q) (side, vertex, edge int) {
+	for i := range p.vs {
+		if p.vs[i].CmpXY(a) == 0 {
+			return 0, i, -1
+		}
+	}
+	wn, edge := winding2q(p.vs, a)
+	if edge >= 0 {
+		return 0, -1, edge
+	}
+	if wn != 0 {
+		return +1, -1, -1
+	}
+	return -1, -1, -1
+}
+
+// winding2q returns the winding number wn of the closed polygonal chain vs around a and edge=-1.
+// If a is on the edge (vs[i],vs[i+1]) for some i, then it returns wn=0 and edge=i instead.
+func winding2q(vs []Point2q, a Point2q) (wn, edge int) {
+	n := len(vs)
+	for i := 0; i < n; i++ {
+		u, v := vs[i], vs[(i+1)%n]
+		o := u.Orientation(v, a)
+		if o == 0 && (Segment2q{u, v}).Contains(a) {
+			return 0, i
+		}
+		//
+		// Count the crossings of the ray from a in the +x direction.
+		//
+		if u.CmpY(a) <= 0 {
+			if v.CmpY(a) > 0 && o > 0 {
+				wn++
+			}
+		} else {
+			if v.CmpY(a) <= 0 && o < 0 {
+				wn--
+			}
+		}
+	}
+	return wn, -1
+}
+
+// String returns a string representation of p in the form "(v0,v1,...)".
+func (p Polygon2q) String() string {
+	s := "("
+	for i, v := range p.vs {
+		if i > 0 {
+			s += ","
+		}
+		s += v.String()
+	}
+	return s + ")"
+}
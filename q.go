@@ -3,15 +3,34 @@
 // Exact computational geometry.
 package pq
 
-import "math/big"
+import (
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+)
 
 // Q represents a rational number of arbitrary precision.
+// Small values are stored inline as a pair of int64 numbers; a value is promoted
+// to big.Rat only if its numerator or denominator overflows int64.
+// The zero value of Q is 0.
 type Q struct {
-	_r *big.Rat
+	// n/d is the value of a small rational number in lowest terms (if _r == nil);
+	// d == 0 stands for d == 1, so that the zero value of Q is 0.
+	n, d int64
+	_r   *big.Rat
 }
 
+// Float64 returns the float64 value nearest to q.
 func (q Q) Float64() float64 {
-	f, _ := q._r.Float64()
+	if q._r == nil {
+		n, d := q.n, q.den()
+		// Both n and d are exact float64 numbers, so the division is correctly rounded.
+		if -1<<53 <= n && n <= 1<<53 && d <= 1<<53 {
+			return float64(n) / float64(d)
+		}
+	}
+	f, _ := r(q).Float64()
 	return f
 }
 
@@ -22,65 +41,191 @@ var qtwo Q = ItoQ(2)
 // RtoQ returns a rational number equal to r.
 func RtoQ(r *big.Rat) Q {
 	if r == nil {
-		return Q{new(big.Rat)}
+		return Q{}
 	}
-	return Q{new(big.Rat).Set(r)}
+	return rtoq(new(big.Rat).Set(r))
 }
 
 // ItoQ returns a rational number equal to n.
 func ItoQ(n int64) Q {
-	return Q{new(big.Rat).SetInt64(n)}
+	if n == math.MinInt64 {
+		return Q{_r: new(big.Rat).SetInt64(n)}
+	}
+	return Q{n, 1, nil}
 }
 
 // FtoQ returns a rational number equal to f. If f is not finite, a run-time panic occurs.
+// Note that f is the binary float64 nearest to a decimal literal such as 0.1;
+// use ParseQ to convert decimal strings exactly.
 func FtoQ(f float64) Q {
+	return must(FtoQErr(f))
+}
+
+// FtoQErr is like FtoQ, but it returns ErrNotFinite if f is not finite.
+func FtoQErr(f float64) (Q, error) {
 	r := new(big.Rat)
 	if r.SetFloat64(f) == nil {
-		panic("not finite")
+		return Q{}, ErrNotFinite
+	}
+	return rtoq(r), nil
+}
+
+// rtoq returns a rational number equal to r, taking ownership of r.
+// The result is stored inline if possible.
+func rtoq(r *big.Rat) Q {
+	if num, den := r.Num(), r.Denom(); num.IsInt64() && den.IsInt64() {
+		if n := num.Int64(); n != math.MinInt64 {
+			return Q{n, den.Int64(), nil}
+		}
+	}
+	return Q{_r: r}
+}
+
+// qtoq returns the small rational number n/d reduced to lowest terms, d > 0.
+func qtoq(n, d int64) Q {
+	if d != 1 {
+		if g := gcd64(n, d); g != 1 {
+			n, d = n/g, d/g
+		}
 	}
-	return Q{r}
+	return Q{n, d, nil}
+}
+
+// den returns the denominator of a small rational number x.
+func (x Q) den() int64 {
+	if x.d == 0 {
+		return 1
+	}
+	return x.d
 }
 
 func r(x Q) *big.Rat {
 	if x._r == nil {
-		return new(big.Rat)
+		return new(big.Rat).SetFrac64(x.n, x.den())
 	}
 	return x._r
 }
 
+// gcd64 returns the greatest common divisor of |a| and b > 0.
+func gcd64(a, b int64) int64 {
+	if a < 0 {
+		a = -a
+	}
+	for a != 0 {
+		a, b = b%a, a
+	}
+	return b
+}
+
+// add64 returns a+b and ok=false if the sum overflows the range of small rational numbers.
+func add64(a, b int64) (int64, bool) {
+	c := a + b
+	if (c > a) != (b > 0) || c == math.MinInt64 {
+		return 0, false
+	}
+	return c, true
+}
+
+// mul64 returns a*b and ok=false if the product overflows the range of small rational numbers.
+func mul64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || c == math.MinInt64 {
+		return 0, false
+	}
+	return c, true
+}
+
 // Neg returns -x.
 func (x Q) Neg() Q {
-	return Q{new(big.Rat).Neg(r(x))}
+	if x._r == nil {
+		return Q{-x.n, x.d, nil}
+	}
+	return rtoq(new(big.Rat).Neg(x._r))
 }
 
 // Abs returns |x|.
 func (x Q) Abs() Q {
-	return Q{new(big.Rat).Abs(r(x))}
+	if x._r == nil {
+		if x.n < 0 {
+			return Q{-x.n, x.d, nil}
+		}
+		return x
+	}
+	return rtoq(new(big.Rat).Abs(x._r))
 }
 
 // Inv returns 1/x.
 func (x Q) Inv() Q {
-	return Q{new(big.Rat).Inv(r(x))}
+	if x._r == nil && x.n != 0 {
+		if x.n < 0 {
+			return Q{-x.den(), -x.n, nil}
+		}
+		return Q{x.den(), x.n, nil}
+	}
+	return rtoq(new(big.Rat).Inv(r(x)))
 }
 
 // Add returns x+y.
 func (x Q) Add(y Q) Q {
-	return Q{new(big.Rat).Add(r(x), r(y))}
+	if x._r == nil && y._r == nil {
+		xd, yd := x.den(), y.den()
+		if xd == yd {
+			if n, ok := add64(x.n, y.n); ok {
+				return qtoq(n, xd)
+			}
+		} else {
+			a, ok1 := mul64(x.n, yd)
+			b, ok2 := mul64(y.n, xd)
+			d, ok3 := mul64(xd, yd)
+			if ok1 && ok2 && ok3 {
+				if n, ok := add64(a, b); ok {
+					return qtoq(n, d)
+				}
+			}
+		}
+	}
+	return rtoq(new(big.Rat).Add(r(x), r(y)))
 }
 
 // Sub returns x-y.
 func (x Q) Sub(y Q) Q {
-	return Q{new(big.Rat).Sub(r(x), r(y))}
+	if y._r == nil {
+		return x.Add(Q{-y.n, y.d, nil})
+	}
+	return rtoq(new(big.Rat).Sub(r(x), r(y)))
 }
 
 // Mul returns x*y.
 func (x Q) Mul(y Q) Q {
-	return Q{new(big.Rat).Mul(r(x), r(y))}
+	if x._r == nil && y._r == nil {
+		xn, xd, yn, yd := x.n, x.den(), y.n, y.den()
+		if g := gcd64(xn, yd); g > 1 {
+			xn, yd = xn/g, yd/g
+		}
+		if g := gcd64(yn, xd); g > 1 {
+			yn, xd = yn/g, xd/g
+		}
+		n, ok1 := mul64(xn, yn)
+		d, ok2 := mul64(xd, yd)
+		if ok1 && ok2 {
+			if n == 0 {
+				d = 1
+			}
+			return Q{n, d, nil}
+		}
+	}
+	return rtoq(new(big.Rat).Mul(r(x), r(y)))
 }
 
 // Div returns x/y.
 func (x Q) Div(y Q) Q {
-	return Q{new(big.Rat).Quo(r(x), r(y))}
+	if y._r == nil && y.n != 0 {
+		return x.Mul(y.Inv())
+	}
+	return rtoq(new(big.Rat).Quo(r(x), r(y)))
 }
 
 // Sgn returns:
@@ -89,7 +234,16 @@ func (x Q) Div(y Q) Q {
 //	 0 if x = 0
 //	+1 if x > 0
 func (x Q) Sgn() int {
-	return r(x).Sign()
+	if x._r == nil {
+		switch {
+		case x.n < 0:
+			return -1
+		case x.n > 0:
+			return +1
+		}
+		return 0
+	}
+	return x._r.Sign()
 }
 
 // Cmp returns:
@@ -98,12 +252,38 @@ func (x Q) Sgn() int {
 //	 0 if x = 0
 //	+1 if x > y
 func (x Q) Cmp(y Q) int {
+	if x._r == nil && y._r == nil {
+		sx, sy := x.Sgn(), y.Sgn()
+		if sx != sy || sx == 0 {
+			switch {
+			case sx < sy:
+				return -1
+			case sx > sy:
+				return +1
+			}
+			return 0
+		}
+		//
+		// Compare |x.n|*y.d with |y.n|*x.d in 128 bits.
+		//
+		xn, yn := uint64(x.Abs().n), uint64(y.Abs().n)
+		h1, l1 := bits.Mul64(xn, uint64(y.den()))
+		h2, l2 := bits.Mul64(yn, uint64(x.den()))
+		c := 0
+		switch {
+		case h1 < h2 || (h1 == h2 && l1 < l2):
+			c = -1
+		case h1 > h2 || (h1 == h2 && l1 > l2):
+			c = +1
+		}
+		return c * sx
+	}
 	return r(x).Cmp(r(y))
 }
 
 // Max returns max{x,y}.
 func (x Q) Max(y Q) Q {
-	if r(x).Cmp(r(y)) > 0 {
+	if x.Cmp(y) > 0 {
 		return x
 	}
 	return y
@@ -111,7 +291,7 @@ func (x Q) Max(y Q) Q {
 
 // Min returns min{x,y}.
 func (x Q) Min(y Q) Q {
-	if r(x).Cmp(r(y)) < 0 {
+	if x.Cmp(y) < 0 {
 		return x
 	}
 	return y
@@ -120,14 +300,17 @@ func (x Q) Min(y Q) Q {
 // Rat returns a big.Rat number equal to x.
 func (x Q) Rat() *big.Rat {
 	if x._r == nil {
-		return new(big.Rat)
+		return new(big.Rat).SetFrac64(x.n, x.den())
 	}
 	return new(big.Rat).Set(x._r)
 }
 
 // String returns a string representation of x.
 func (x Q) String() string {
-	return r(x).String()
+	if x._r == nil {
+		return strconv.FormatInt(x.n, 10) + "/" + strconv.FormatInt(x.den(), 10)
+	}
+	return x._r.String()
 }
 
 // Det2x2 computes the determinant of a 2-by-2 matrix.
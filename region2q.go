@@ -0,0 +1,316 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import "sort"
+
+// Region2q represents a polygonal region in the 2-dimensional Euclidean plane, i.e., a finite union
+// of polygons with holes. It is given by a set of rings: the outer boundaries are counter-clockwise
+// and the boundaries of the holes are clockwise. A point belongs to the region if the winding number
+// of the rings around it is nonzero.
+type Region2q struct {
+	rings []Polygon2q
+}
+
+// PolytoReg returns the region bounded by the simple polygon outer with the given holes.
+// The orientations of outer and holes are normalized.
+func PolytoReg(outer Polygon2q, holes ...Polygon2q) Region2q {
+	rings := make([]Polygon2q, 0, 1+len(holes))
+	if outer.Orientation() < 0 {
+		outer = outer.Reverse()
+	}
+	rings = append(rings, PtoPoly(outer.vs))
+	for _, h := range holes {
+		if h.Orientation() > 0 {
+			h = h.Reverse()
+		}
+		rings = append(rings, PtoPoly(h.vs))
+	}
+	return Region2q{rings}
+}
+
+// Rings returns the boundary rings of r.
+func (r Region2q) Rings() []Polygon2q {
+	return r.rings
+}
+
+// Area returns the area of r.
+func (r Region2q) Area() Q {
+	sum := qzer
+	for _, p := range r.rings {
+		sum = sum.Add(p.Area())
+	}
+	return sum
+}
+
+// Side returns:
+//
+//	-1 if a is outside r
+//	 0 if a is on the boundary of r
+//	+1 if a is inside r
+func (r Region2q) Side(a Point2q) int {
+	wn := 0
+	for _, p := range r.rings {
+		k, edge := winding2q(p.vs, a)
+		if edge >= 0 {
+			return 0
+		}
+		wn += k
+	}
+	if wn != 0 {
+		return +1
+	}
+	return -1
+}
+
+// Union returns the union of r and s.
+func (r Region2q) Union(s Region2q) Region2q {
+	return overlay2q(r, s, func(a, b bool) bool { return a || b })
+}
+
+// Intersection returns the intersection of r and s.
+func (r Region2q) Intersection(s Region2q) Region2q {
+	return overlay2q(r, s, func(a, b bool) bool { return a && b })
+}
+
+// Difference returns the difference of r and s.
+func (r Region2q) Difference(s Region2q) Region2q {
+	return overlay2q(r, s, func(a, b bool) bool { return a && !b })
+}
+
+// Xor returns the symmetric difference of r and s.
+func (r Region2q) Xor(s Region2q) Region2q {
+	return overlay2q(r, s, func(a, b bool) bool { return a != b })
+}
+
+// String returns a string representation of r in the form "(ring0,ring1,...)".
+func (r Region2q) String() string {
+	s := "("
+	for i, p := range r.rings {
+		if i > 0 {
+			s += ","
+		}
+		s += p.String()
+	}
+	return s + ")"
+}
+
+// overlay2q computes the region {p: op(p∈r,p∈s)}. The boundaries of r and s are split at their
+// intersection points, the winding numbers on both sides of each piece are computed by a plane
+// sweep, and the pieces separating the result from its complement are linked into rings.
+// The output rings have no collinear consecutive vertices, each of them starts at its vertex
+// that is the first in (x,y)-order, and they are sorted by that vertex.
+//
+// Reference: F. Martinez, A.J. Rueda, F.R. Feito, A new algorithm for computing Boolean operations
+// on polygons, Comput. Geosci., 35:1177-1185 (2009).
+//
+// See: http://dx.doi.org/10.1016/j.cageo.2008.08.009
+func overlay2q(r, s Region2q, op func(a, b bool) bool) Region2q {
+	//
+	// Collect the edges; owner[k] tells which region the k-th edge comes from.
+	//
+	ss := make([]Segment2q, 0)
+	owner := make([]int, 0)
+	for k, reg := range []Region2q{r, s} {
+		for _, p := range reg.rings {
+			n := len(p.vs)
+			for i := 0; i < n; i++ {
+				e := p.Edge(i)
+				if !e.Degenerate() {
+					ss = append(ss, e)
+					owner = append(owner, k)
+				}
+			}
+		}
+	}
+	//
+	// Split the edges at the intersection points.
+	//
+	cuts := make([][]Point2q, len(ss))
+	for k, e := range ss {
+		cuts[k] = []Point2q{e.a, e.b}
+	}
+	for _, c := range Crossings2q(ss) {
+		for _, k := range c.segs {
+			cuts[k] = append(cuts[k], c.p)
+		}
+	}
+	vs := make([]Point2q, 0)
+	for _, cut := range cuts {
+		vs = append(vs, cut...)
+	}
+	sort.Sort(p2qs(vs))
+	m := 0
+	for i := range vs {
+		if i == 0 || vs[i].CmpXY(vs[m-1]) != 0 {
+			vs[m] = vs[i]
+			m++
+		}
+	}
+	vs = vs[:m]
+	id := func(p Point2q) int {
+		return sort.Search(len(vs), func(i int) bool { return vs[i].CmpXY(p) >= 0 })
+	}
+	//
+	// Each piece (lo,hi), lo<hi, accumulates the multiplicities of the directed edges lying on it.
+	//
+	type piece struct{ lo, hi int }
+	type wind struct{ r, s int }
+	delta := make(map[piece]wind)
+	for k, cut := range cuts {
+		fwd := ss[k].a.CmpXY(ss[k].b) < 0
+		sort.Sort(p2qs(cut))
+		for i := 1; i < len(cut); i++ {
+			if cut[i].CmpXY(cut[i-1]) == 0 {
+				continue
+			}
+			pc := piece{id(cut[i-1]), id(cut[i])}
+			d := delta[pc]
+			w := -1
+			if fwd {
+				w = +1
+			}
+			if owner[k] == 0 {
+				d.r += w
+			} else {
+				d.s += w
+			}
+			delta[pc] = d
+		}
+	}
+	start := make([][]int, len(vs))
+	for pc := range delta {
+		start[pc.lo] = append(start[pc.lo], pc.hi)
+	}
+	//
+	// Sweep the pieces in (x,y)-order. The status holds the pieces crossing the sweep line
+	// ordered from below to above, together with the winding numbers above them.
+	//
+	type item struct {
+		pc    piece
+		above wind
+	}
+	status := make([]item, 0)
+	type dedge struct{ u, v int }
+	out := make([]dedge, 0)
+	for v := range vs {
+		p := vs[v]
+		i := sort.Search(len(status), func(k int) bool {
+			pc := status[k].pc
+			return vs[pc.lo].Orientation(vs[pc.hi], p) <= 0
+		})
+		j := i
+		for j < len(status) && status[j].pc.hi == v {
+			j++
+		}
+		//
+		// The pieces starting at p ordered by slope.
+		//
+		his := start[v]
+		sort.Slice(his, func(a, b int) bool { return p.Orientation(vs[his[a]], vs[his[b]]) > 0 })
+		below := wind{}
+		if i > 0 {
+			below = status[i-1].above
+		}
+		next := make([]item, len(his))
+		for k, hi := range his {
+			pc := piece{v, hi}
+			d := delta[pc]
+			above := wind{below.r + d.r, below.s + d.s}
+			in0 := op(below.r != 0, below.s != 0)
+			in1 := op(above.r != 0, above.s != 0)
+			switch {
+			case in1 && !in0:
+				out = append(out, dedge{v, hi})
+			case in0 && !in1:
+				out = append(out, dedge{hi, v})
+			}
+			next[k] = item{pc, above}
+			below = above
+		}
+		tail := append(next, status[j:]...)
+		status = append(status[:i], tail...)
+	}
+	//
+	// Link the boundary edges into rings keeping the result on the left.
+	//
+	from := make([][]int, len(vs))
+	for k, e := range out {
+		from[e.u] = append(from[e.u], k)
+	}
+	used := make([]bool, len(out))
+	rings := make([]Polygon2q, 0)
+	for k0 := range out {
+		if used[k0] {
+			continue
+		}
+		//
+		// A vertex visited twice closes a loop, which is split off as a separate ring.
+		//
+		stack := make([]int, 0)
+		at := make(map[int]int)
+		for k := k0; !used[k]; {
+			used[k] = true
+			e := out[k]
+			if i, ok := at[e.u]; ok {
+				rings = append(rings, ring2q(vs, stack[i:]))
+				for _, u := range stack[i+1:] {
+					delete(at, u)
+				}
+				stack = stack[:i]
+			}
+			at[e.u] = len(stack)
+			stack = append(stack, e.u)
+			cand := make([]int, 0, len(from[e.v]))
+			ws := make([]Point2q, 0, len(from[e.v]))
+			for _, h := range from[e.v] {
+				if !used[h] || h == k0 {
+					cand = append(cand, h)
+					ws = append(ws, vs[out[h].v])
+				}
+			}
+			if len(cand) == 0 {
+				break
+			}
+			k = cand[cwturn2q(vs[e.v], vs[e.u], ws)]
+		}
+		rings = append(rings, ring2q(vs, stack))
+	}
+	//
+	// A ring of collinear edges has fewer than 3 vertices left and zero area.
+	//
+	k := 0
+	for _, p := range rings {
+		if len(p.vs) >= 3 {
+			rings[k] = p
+			k++
+		}
+	}
+	rings = rings[:k]
+	sort.Slice(rings, func(a, b int) bool {
+		if c := rings[a].vs[0].CmpXY(rings[b].vs[0]); c != 0 {
+			return c < 0
+		}
+		return rings[a].vs[1].CmpXY(rings[b].vs[1]) < 0
+	})
+	return Region2q{rings}
+}
+
+// ring2q returns the ring vs[ids] without the collinear consecutive vertices, rotated to start at
+// the vertex that is the first in (x,y)-order.
+func ring2q(vs []Point2q, ids []int) Polygon2q {
+	res := make([]Point2q, 0, len(ids))
+	n := len(ids)
+	for i := range ids {
+		if vs[ids[(i+n-1)%n]].Orientation(vs[ids[i]], vs[ids[(i+1)%n]]) != 0 {
+			res = append(res, vs[ids[i]])
+		}
+	}
+	m := 0
+	for i := range res {
+		if res[i].CmpXY(res[m]) < 0 {
+			m = i
+		}
+	}
+	return Polygon2q{append(res[m:], res[:m]...)}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// Segment2q represents a closed line segment in the 2-dimensional Euclidean plane.
+type Segment2q struct {
+	a, b Point2q
+}
+
+// The kinds of intersection of two segments returned by Segment2q.Intersection.
+const (
+	SegDisjoint = iota // the segments have no common points
+	SegProper          // the segments cross at a single point interior to both of them
+	SegTouch           // the segments have a single common point, which is an endpoint of at least one of them
+	SegOverlap         // the segments are collinear and have a common sub-segment of positive length
+)
+
+// PPtoSeg returns the segment [a,b].
+func PPtoSeg(a, b Point2q) Segment2q {
+	return Segment2q{a, b}
+}
+
+// A returns the first endpoint of s.
+func (s Segment2q) A() Point2q {
+	return s.a
+}
+
+// B returns the second endpoint of s.
+func (s Segment2q) B() Point2q {
+	return s.b
+}
+
+// AB returns the endpoints of s.
+func (s Segment2q) AB() (a, b Point2q) {
+	return s.a, s.b
+}
+
+// Degenerate reports whether the endpoints of s coincide.
+func (s Segment2q) Degenerate() bool {
+	return s.a.CmpXY(s.b) == 0
+}
+
+// Contains reports whether p lies on s (endpoints included).
+func (s Segment2q) Contains(p Point2q) bool {
+	if s.a.Orientation(s.b, p) != 0 {
+		return false
+	}
+	lo, hi := s.a, s.b
+	if lo.CmpXY(hi) > 0 {
+		lo, hi = hi, lo
+	}
+	return lo.CmpXY(p) <= 0 && p.CmpXY(hi) <= 0
+}
+
+// Intersection classifies the intersection of s and t. It returns:
+//
+//	SegDisjoint         and p=q=(0,0)
+//	SegProper, SegTouch and p=q the common point
+//	SegOverlap          and [p,q] the common sub-segment, p<q in (x,y)-order
+func (s Segment2q) Intersection(t Segment2q) (kind int, p, q Point2q) {
+	o1 := s.a.Orientation(s.b, t.a)
+	o2 := s.a.Orientation(s.b, t.b)
+	o3 := t.a.Orientation(t.b, s.a)
+	o4 := t.a.Orientation(t.b, s.b)
+	//
+	// Collinear segments (including degenerate ones).
+	//
+	if o1 == 0 && o2 == 0 && o3 == 0 && o4 == 0 {
+		s0, s1 := s.a, s.b
+		if s0.CmpXY(s1) > 0 {
+			s0, s1 = s1, s0
+		}
+		t0, t1 := t.a, t.b
+		if t0.CmpXY(t1) > 0 {
+			t0, t1 = t1, t0
+		}
+		lo, hi := s0, t1
+		if t0.CmpXY(s0) > 0 {
+			lo = t0
+		}
+		if s1.CmpXY(t1) < 0 {
+			hi = s1
+		}
+		switch lo.CmpXY(hi) {
+		case 0:
+			return SegTouch, lo, lo
+		case -1:
+			return SegOverlap, lo, hi
+		}
+		return SegDisjoint, Point2q{}, Point2q{}
+	}
+	//
+	// The endpoints of each segment must not lie strictly on the same side of the other one.
+	//
+	if o1*o2 > 0 || o3*o4 > 0 {
+		return SegDisjoint, Point2q{}, Point2q{}
+	}
+	switch {
+	case o1 == 0:
+		return SegTouch, t.a, t.a
+	case o2 == 0:
+		return SegTouch, t.b, t.b
+	case o3 == 0:
+		return SegTouch, s.a, s.a
+	case o4 == 0:
+		return SegTouch, s.b, s.b
+	}
+	//
+	// Proper crossing: p = s.a + λ(s.b-s.a).
+	//
+	u, v, w := s.a.Vector(s.b), t.a.Vector(t.b), s.a.Vector(t.a)
+	lambda := Det2x2(w.x, w.y, v.x, v.y).Div(Det2x2(u.x, u.y, v.x, v.y))
+	p = s.a.Add(u.Mul(lambda))
+	return SegProper, p, p
+}
+
+// String returns a string representation of s in the form "[a,b]".
+func (s Segment2q) String() string {
+	return "[" + s.a.String() + "," + s.b.String() + "]"
+}
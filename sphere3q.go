@@ -0,0 +1,119 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+// Sphere3q represents a sphere in the 3-dimensional Euclidean space.
+type Sphere3q struct {
+	cen Point3q
+	rsq Q
+}
+
+// CR2toSph returns a sphere with a given center and radius squared.
+// If radius2 is negative, a run-time panic occurs.
+func CR2toSph(center Point3q, radius2 Q) Sphere3q {
+	return must(CR2toSphErr(center, radius2))
+}
+
+// CR2toSphErr is like CR2toSph, but it returns ErrNegativeRadius if radius2 is negative.
+func CR2toSphErr(center Point3q, radius2 Q) (Sphere3q, error) {
+	if radius2.Sgn() < 0 {
+		return Sphere3q{}, ErrNegativeRadius
+	}
+	return Sphere3q{center, radius2}, nil
+}
+
+// PPtoSph returns a sphere having the segment [a,b] as its diameter.
+func PPtoSph(a, b Point3q) Sphere3q {
+	cen := a.Midpoint(b)
+	rsq := a.Dist2(cen)
+	return Sphere3q{cen, rsq}
+}
+
+// PPPtoSph returns the smallest sphere passing through three given points.
+// Its center lies in the plane of (a,b,c). If the points are collinear and distinct,
+// a run-time panic occurs.
+func PPPtoSph(a, b, c Point3q) Sphere3q {
+	return must(PPPtoSphErr(a, b, c))
+}
+
+// PPPtoSphErr is like PPPtoSph, but it returns ErrCollinear if (a,b,c) are collinear and distinct.
+func PPPtoSphErr(a, b, c Point3q) (Sphere3q, error) {
+	u, v := a.Vector(b), a.Vector(c)
+	uv := u.Crs(v)
+	// Test if (a,b,c) are collinear.
+	if uv.MaxAbs().Sgn() == 0 {
+		if a.CmpXYZ(b) == 0 {
+			return PPtoSph(b, c), nil
+		}
+		if b.CmpXYZ(c) == 0 {
+			return PPtoSph(c, a), nil
+		}
+		if c.CmpXYZ(a) == 0 {
+			return PPtoSph(a, b), nil
+		}
+		return Sphere3q{}, ErrCollinear
+	}
+	//
+	// The circumcenter is a+((|u|²v-|v|²u)×(u×v))/(2|u×v|²).
+	//
+	w := (v.Mul(u.Abs2())).Sub(u.Mul(v.Abs2())).Crs(uv)
+	cen := a.Add(w.Div(uv.Abs2().Mul(qtwo)))
+	rsq := cen.Dist2(a)
+	return Sphere3q{cen, rsq}, nil
+}
+
+// PPPPtoSph returns a sphere passing through four given points. If the points are coplanar
+// and no two of them are equal, or if three of them are collinear and distinct,
+// a run-time panic occurs.
+func PPPPtoSph(a, b, c, d Point3q) Sphere3q {
+	return must(PPPPtoSphErr(a, b, c, d))
+}
+
+// PPPPtoSphErr is like PPPPtoSph, but it returns ErrCoplanar or ErrCollinear instead of panicking.
+func PPPPtoSphErr(a, b, c, d Point3q) (Sphere3q, error) {
+	// Test if (a,b,c,d) are coplanar.
+	if a.Orientation(b, c, d) == 0 {
+		switch {
+		case a.CmpXYZ(b) == 0, a.CmpXYZ(c) == 0, a.CmpXYZ(d) == 0:
+			return PPPtoSphErr(b, c, d)
+		case b.CmpXYZ(c) == 0, b.CmpXYZ(d) == 0:
+			return PPPtoSphErr(a, c, d)
+		case c.CmpXYZ(d) == 0:
+			return PPPtoSphErr(a, b, d)
+		}
+		return Sphere3q{}, ErrCoplanar
+	}
+	//
+	// The circumcenter is a+(|u|²(v×w)+|v|²(w×u)+|w|²(u×v))/(2u·(v×w)).
+	//
+	u, v, w := a.Vector(b), a.Vector(c), a.Vector(d)
+	vw := v.Crs(w)
+	t := (vw.Mul(u.Abs2())).Add(w.Crs(u).Mul(v.Abs2())).Add(u.Crs(v).Mul(w.Abs2()))
+	cen := a.Add(t.Div(u.Dot(vw).Mul(qtwo)))
+	rsq := cen.Dist2(a)
+	return Sphere3q{cen, rsq}, nil
+}
+
+// Center returns the center of s.
+func (s Sphere3q) Center() Point3q {
+	return s.cen
+}
+
+// Radius2 returns the radius squared of s.
+func (s Sphere3q) Radius2() Q {
+	return s.rsq
+}
+
+// Side returns:
+//
+//	-1 if a is outside s
+//	 0 if a is on s
+//	+1 if a is inside s
+func (s Sphere3q) Side(a Point3q) int {
+	return s.rsq.Cmp(s.cen.Dist2(a))
+}
+
+// String returns a string representation of s in the form "(center,radius2)".
+func (s Sphere3q) String() string {
+	return "(" + s.cen.String() + "," + s.rsq.String() + ")"
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+)
+
+// WriteSTL writes the mesh (vs,fs) to w in the STL format, in ASCII or in binary. The faces
+// must be convex; they are triangulated as fans from their first vertices. The facet normals
+// are unit vectors rounded to float32; a triangle with zero area has the normal (0,0,0).
+//
+// See: http://www.fabbers.com/tech/STL_Format
+func WriteSTL(w io.Writer, vs []Point3q, fs [][]int, bin, rational bool) error {
+	if err := meshcheck(len(vs), fs); err != nil {
+		return err
+	}
+	var tris [][3]Point3q
+	for _, f := range fs {
+		for j := 2; j < len(f); j++ {
+			tris = append(tris, [3]Point3q{vs[f[0]], vs[f[j-1]], vs[f[j]]})
+		}
+	}
+	b := bufio.NewWriter(w)
+	if !bin {
+		b.WriteString("solid pq\n")
+		for _, t := range tris {
+			n := stlnormal(t)
+			b.WriteString("facet normal " + stlnum(n[0]) + " " + stlnum(n[1]) + " " + stlnum(n[2]) + "\n")
+			b.WriteString(" outer loop\n")
+			for _, a := range t {
+				b.WriteString("  vertex " + meshxyz(a, rational) + "\n")
+			}
+			b.WriteString(" endloop\nendfacet\n")
+		}
+		b.WriteString("endsolid pq\n")
+		return b.Flush()
+	}
+	var head [80]byte
+	copy(head[:], "binary STL written by pq")
+	b.Write(head[:])
+	var buf [4]byte
+	put := func(f float32) {
+		binary.LittleEndian.PutUint32(buf[:], math.Float32bits(f))
+		b.Write(buf[:])
+	}
+	binary.LittleEndian.PutUint32(buf[:], uint32(len(tris)))
+	b.Write(buf[:])
+	for _, t := range tris {
+		for _, f := range stlnormal(t) {
+			put(f)
+		}
+		for _, a := range t {
+			for _, x := range [3]Q{a.x, a.y, a.z} {
+				f, err := meshfloat32(x, rational)
+				if err != nil {
+					return err
+				}
+				put(f)
+			}
+		}
+		b.Write([]byte{0, 0})
+	}
+	return b.Flush()
+}
+
+// stlnormal returns the unit normal of the triangle t rounded to float32.
+func stlnormal(t [3]Point3q) [3]float32 {
+	n := t[0].Vector(t[1]).Crs(t[0].Vector(t[2]))
+	x, y, z := n.x.Float64(), n.y.Float64(), n.z.Float64()
+	l := math.Sqrt(x*x + y*y + z*z)
+	if n.x.Sgn() == 0 && n.y.Sgn() == 0 && n.z.Sgn() == 0 || l == 0 || math.IsInf(l, 0) {
+		return [3]float32{}
+	}
+	return [3]float32{float32(x / l), float32(y / l), float32(z / l)}
+}
+
+// stlnum returns f in the shortest form.
+func stlnum(f float32) string {
+	return strconv.FormatFloat(float64(f), 'g', -1, 32)
+}
+
+// ReadSTL reads a mesh in the STL format from r, in ASCII or in binary. The vertices of
+// the triangles are merged if they are equal; the normals and the attributes are ignored.
+// A binary file is recognized by its size, 84 bytes plus 50 bytes per triangle.
+func ReadSTL(r io.Reader) (vs []Point3q, fs [][]int, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	vs, fs = make([]Point3q, 0), make([][]int, 0)
+	index := make(map[string]int)
+	add := func(a Point3q) int {
+		key := a.String()
+		i, ok := index[key]
+		if !ok {
+			i = len(vs)
+			index[key] = i
+			vs = append(vs, a)
+		}
+		return i
+	}
+	if len(data) >= 84 && uint64(len(data)) == 84+50*uint64(binary.LittleEndian.Uint32(data[80:])) {
+		for d := data[84:]; len(d) > 0; d = d[50:] {
+			var f [3]int
+			for k := range f {
+				var c [3]Q
+				for j := range c {
+					bits := binary.LittleEndian.Uint32(d[12+12*k+4*j:])
+					if c[j], err = meshf(float64(math.Float32frombits(bits))); err != nil {
+						return nil, nil, err
+					}
+				}
+				f[k] = add(Point3q{c[0], c[1], c[2]})
+			}
+			fs = append(fs, f[:])
+		}
+		return vs, fs, nil
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("solid")) {
+		return nil, nil, errors.New("pq: STL: invalid file")
+	}
+	lines, err := meshlines(bytes.NewReader(data), 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var f []int
+	for _, l := range lines {
+		switch l[0] {
+		case "vertex":
+			if len(l) != 4 {
+				return nil, nil, errors.New("pq: STL: a vertex must have 3 coordinates")
+			}
+			a, err := meshp(l[1:])
+			if err != nil {
+				return nil, nil, err
+			}
+			f = append(f, add(a))
+		case "endloop":
+			if len(f) != 3 {
+				return nil, nil, errors.New("pq: STL: a facet must have 3 vertices")
+			}
+			fs = append(fs, f)
+			f = nil
+		}
+	}
+	return vs, fs, nil
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// SVG is a Scalable Vector Graphics document showing planar geometry. The items are drawn
+// in the order they are added; the document is scaled to fit the bounding box of all items
+// into the given width. Each item has a style, a CSS declaration list such as
+// "stroke:red;fill:none", which replaces the default style of the item if not empty.
+// If labels are enabled, then the points drawn by Points and the centers of the circles
+// are labeled with their exact coordinates.
+//
+// See: https://www.w3.org/TR/SVG11/
+type SVG struct {
+	width  int
+	labels bool
+	items  []svgitem
+}
+
+// svgitem is an item of an SVG document.
+type svgitem struct {
+	kind  int // svgPoints, svgPolyline, svgPolygon, svgPath or svgCircle
+	ps    []Point2q
+	rings []Polygon2q
+	cir   Circle2q
+	style string
+}
+
+const (
+	svgPoints = iota
+	svgPolyline
+	svgPolygon
+	svgPath
+	svgCircle
+)
+
+// svgmargin is the margin of an SVG document in pixels.
+const svgmargin = 20
+
+var errSVGWidth = fmt.Errorf("%w: SVG width not greater than 40", ErrInvalid)
+
+// NewSVG returns an empty SVG document of the given width in pixels, which must be greater
+// than 40. If labels is true, then the points are labeled with their exact coordinates.
+// If width <= 40, a run-time panic occurs.
+func NewSVG(width int, labels bool) *SVG {
+	return must(NewSVGErr(width, labels))
+}
+
+// NewSVGErr is like NewSVG, but it returns an error wrapping ErrInvalid if width <= 40.
+func NewSVGErr(width int, labels bool) (*SVG, error) {
+	if width <= 2*svgmargin {
+		return nil, errSVGWidth
+	}
+	return &SVG{width, labels, nil}, nil
+}
+
+// Points draws the points ps.
+func (s *SVG) Points(ps []Point2q, style string) {
+	s.add(svgitem{kind: svgPoints, ps: append([]Point2q{}, ps...)}, style, "fill:black")
+}
+
+// Polyline draws the polyline ps.
+func (s *SVG) Polyline(ps []Point2q, style string) {
+	s.add(svgitem{kind: svgPolyline, ps: append([]Point2q{}, ps...)}, style, "stroke:black;fill:none")
+}
+
+// Hull draws the convex hull given by the lower hull and the upper hull computed by ConvHull2q.
+func (s *SVG) Hull(lower, upper []Point2q, style string) {
+	s.add(svgitem{kind: svgPolygon, ps: HulltoPoly(lower, upper).vs}, style, "stroke:blue;fill:none")
+}
+
+// Polygon draws the polygon p.
+func (s *SVG) Polygon(p Polygon2q, style string) {
+	s.add(svgitem{kind: svgPolygon, ps: p.Vertices()}, style, "stroke:black;fill:none")
+}
+
+// Region draws the region r; the interior is filled by the nonzero winding rule.
+func (s *SVG) Region(r Region2q, style string) {
+	s.add(svgitem{kind: svgPath, rings: r.Rings()}, style, "stroke:black;fill:silver")
+}
+
+// Triangles draws the triangles tris with vertices from ps, e.g., a triangulation computed
+// by Delaunay2q or Triangulate2q.
+func (s *SVG) Triangles(ps []Point2q, tris [][3]int, style string) {
+	rings := make([]Polygon2q, len(tris))
+	for k, t := range tris {
+		rings[k] = Polygon2q{[]Point2q{ps[t[0]], ps[t[1]], ps[t[2]]}}
+	}
+	s.add(svgitem{kind: svgPath, rings: rings}, style, "stroke:gray;fill:none")
+}
+
+// Circle draws the circle c.
+func (s *SVG) Circle(c Circle2q, style string) {
+	s.add(svgitem{kind: svgCircle, cir: c}, style, "stroke:red;fill:none")
+}
+
+// MinCircle draws the points ps, their convex hull and the smallest enclosing circle computed
+// by MinCircle2q, together with the support points defining it: a single point, two points
+// spanning a diameter, or three points on the circle. The slice ps is not modified.
+func (s *SVG) MinCircle(ps []Point2q, style string) {
+	if len(ps) == 0 {
+		return
+	}
+	s.Points(ps, "")
+	lower, upper := ConvHull2q(append([]Point2q{}, ps...))
+	s.Hull(lower, upper, "")
+	sup := MinCircle2(append([]Point2q{}, ps...))
+	s.Circle(suptoCir(sup), style)
+	if len(sup) > 1 {
+		s.Polygon(Polygon2q{sup}, "stroke:red;stroke-dasharray:4")
+	}
+	s.Points(sup, "fill:red")
+}
+
+// add appends the item with the style, or the default style if style is empty.
+func (s *SVG) add(it svgitem, style, def string) {
+	if style == "" {
+		style = def
+	}
+	it.style = style
+	s.items = append(s.items, it)
+}
+
+// Bytes returns the SVG document.
+func (s *SVG) Bytes() []byte {
+	//
+	// Compute the bounding box of all items exactly; only the radii of the circles are rounded.
+	//
+	xmin, ymin, xmax, ymax := qzer, qzer, qzer, qzer
+	empty := true
+	bbox := func(x, y, r Q) {
+		if empty {
+			xmin, ymin, xmax, ymax = x.Sub(r), y.Sub(r), x.Add(r), y.Add(r)
+			empty = false
+			return
+		}
+		xmin, xmax = xmin.Min(x.Sub(r)), xmax.Max(x.Add(r))
+		ymin, ymax = ymin.Min(y.Sub(r)), ymax.Max(y.Add(r))
+	}
+	for _, it := range s.items {
+		for _, p := range it.ps {
+			bbox(p.x, p.y, qzer)
+		}
+		for _, ring := range it.rings {
+			for _, p := range ring.vs {
+				bbox(p.x, p.y, qzer)
+			}
+		}
+		if it.kind == svgCircle {
+			bbox(it.cir.cen.x, it.cir.cen.y, svgsqrt(it.cir.rsq))
+		}
+	}
+	//
+	// The y-axis points up; the scale fits the larger side of the bounding box. The pixel
+	// coordinates are computed exactly and rounded only when they are written, so that
+	// neither huge nor tiny coordinates are lost to float64.
+	//
+	span := xmax.Sub(xmin).Max(ymax.Sub(ymin))
+	if span.Sgn() == 0 {
+		span = qone
+	}
+	scale := ItoQ(int64(s.width - 2*svgmargin)).Div(span)
+	height := int(math.Ceil(ymax.Sub(ymin).Mul(scale).Float64())) + 2*svgmargin
+	left, top := ItoQ(svgmargin), ItoQ(int64(height-svgmargin))
+	tx := func(x Q) string { return svgnum(left.Add(x.Sub(xmin).Mul(scale)).Float64()) }
+	ty := func(y Q) string { return svgnum(top.Sub(y.Sub(ymin).Mul(scale)).Float64()) }
+	pts := func(ps []Point2q) string {
+		s := make([]string, len(ps))
+		for i, p := range ps {
+			s[i] = tx(p.x) + "," + ty(p.y)
+		}
+		return strings.Join(s, " ")
+	}
+	//
+	var b strings.Builder
+	w, h := strconv.Itoa(s.width), strconv.Itoa(height)
+	b.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="` + w + `" height="` + h +
+		`" viewBox="0 0 ` + w + " " + h + `">` + "\n")
+	for _, it := range s.items {
+		style := ` style="` + svgescape(it.style) + `"`
+		switch it.kind {
+		case svgPoints:
+			b.WriteString("<g" + style + ">\n")
+			for _, p := range it.ps {
+				b.WriteString(`<circle cx="` + tx(p.x) + `" cy="` + ty(p.y) + `" r="3"/>` + "\n")
+			}
+			b.WriteString("</g>\n")
+		case svgPolyline:
+			b.WriteString(`<polyline points="` + pts(it.ps) + `"` + style + "/>\n")
+		case svgPolygon:
+			b.WriteString(`<polygon points="` + pts(it.ps) + `"` + style + "/>\n")
+		case svgPath:
+			d := make([]string, len(it.rings))
+			for k, ring := range it.rings {
+				d[k] = "M" + pts(ring.vs) + "Z"
+			}
+			b.WriteString(`<path d="` + strings.Join(d, " ") + `" fill-rule="nonzero"` + style + "/>\n")
+		case svgCircle:
+			r := svgnum(svgsqrt(it.cir.rsq).Mul(scale).Float64())
+			b.WriteString(`<circle cx="` + tx(it.cir.cen.x) + `" cy="` + ty(it.cir.cen.y) + `" r="` + r + `"` + style + "/>\n")
+		}
+	}
+	//
+	// The labels are drawn on top of all items.
+	//
+	if s.labels {
+		b.WriteString(`<g style="font:10px sans-serif;fill:black">` + "\n")
+		for _, it := range s.items {
+			var ps []Point2q
+			switch it.kind {
+			case svgPoints:
+				ps = it.ps
+			case svgCircle:
+				ps = []Point2q{it.cir.cen}
+			}
+			for _, p := range ps {
+				b.WriteString(`<text x="` + tx(p.x) + `" y="` + ty(p.y) + `" dx="4" dy="-4">` +
+					svgescape("("+FormatQ(p.x)+","+FormatQ(p.y)+")") + "</text>\n")
+			}
+		}
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</svg>\n")
+	return []byte(b.String())
+}
+
+// svgsqrt returns the square root of x ≥ 0 rounded to 64 bits.
+func svgsqrt(x Q) Q {
+	f := new(big.Float).SetPrec(64).SetRat(r(x))
+	q, _ := f.Sqrt(f).Rat(nil)
+	return rtoq(q)
+}
+
+// svgnum returns x with two decimal places.
+func svgnum(x float64) string {
+	return strconv.FormatFloat(x, 'f', 2, 64)
+}
+
+// svgescape returns s with the XML special characters escaped.
+func svgescape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,340 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import "sort"
+
+// Triangulate2q computes a triangulation of a simple polygon with holes. The holes must be
+// simple polygons lying in the interior of outer and in the exterior of each other; each
+// polygon may be clockwise or counter-clockwise. The vertices are numbered consecutively:
+// first the vertices of outer, then the vertices of holes[0], holes[1], etc. Each triangle
+// is a triple of vertex numbers listed in counter-clockwise order; no triangle has zero area.
+// Collinear vertices are handled deterministically.
+//
+// The polygon is decomposed into x-monotone pieces by a plane sweep in (x,y)-order,
+// then each piece is triangulated by ear clipping.
+//
+// Reference: D.T. Lee, F.P. Preparata, Location of a point in a planar subdivision and its
+// applications, SIAM J. Comput., 6:594-606 (1977).
+//
+// See: http://dx.doi.org/10.1137/0206043
+//
+// Reference: M. de Berg, O. Cheong, M. van Kreveld, M. Overmars, Computational Geometry:
+// Algorithms and Applications, 3rd ed., Springer, pp 49-58 (2008).
+//
+// If ear clipping finds no ear in a monotone piece, which can happen only if the conditions
+// above are violated, a run-time panic occurs; no partial triangulation is returned.
+func Triangulate2q(outer Polygon2q, holes ...Polygon2q) [][3]int {
+	return must(Triangulate2qErr(outer, holes...))
+}
+
+// Triangulate2qErr is like Triangulate2q, but it returns ErrNotSimple if a monotone piece
+// cannot be triangulated, i.e., ear clipping finds no ear.
+func Triangulate2qErr(outer Polygon2q, holes ...Polygon2q) ([][3]int, error) {
+	//
+	// Link the rings so that the interior is on the left of each edge (i,nxt[i]).
+	//
+	pts := make([]Point2q, 0)
+	nxt := make([]int, 0)
+	prv := make([]int, 0)
+	ring := func(p Polygon2q, ccw bool) {
+		first, n := len(pts), len(p.vs)
+		pts = append(pts, p.vs...)
+		for i := 0; i < n; i++ {
+			f, b := first+(i+1)%n, first+(i+n-1)%n
+			if !ccw {
+				f, b = b, f
+			}
+			nxt = append(nxt, f)
+			prv = append(prv, b)
+		}
+	}
+	if len(outer.vs) < 3 || outer.Orientation() == 0 {
+		return [][3]int{}, nil
+	}
+	ring(outer, outer.Orientation() > 0)
+	for _, h := range holes {
+		if len(h.vs) < 3 || h.Orientation() == 0 {
+			continue
+		}
+		ring(h, h.Orientation() < 0)
+	}
+	diags := monotone2q(pts, nxt, prv)
+	//
+	// Each face of the polygon subdivided by the diagonals is x-monotone.
+	//
+	res := make([][3]int, 0, len(pts))
+	for _, face := range faces2q(pts, nxt, prv, diags) {
+		var ok bool
+		if res, ok = earclip2q(pts, face, res); !ok {
+			return nil, ErrNotSimple
+		}
+	}
+	return res, nil
+}
+
+// Vertex types of the monotone decomposition.
+const (
+	vtxStart = iota
+	vtxEnd
+	vtxSplit
+	vtxMerge
+	vtxRegular
+)
+
+// monotone2q returns the diagonals decomposing the polygon given by the rings (pts,nxt,prv)
+// into x-monotone pieces.
+func monotone2q(pts []Point2q, nxt, prv []int) [][2]int {
+	n := len(pts)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return pts[order[a]].CmpXY(pts[order[b]]) < 0 })
+	//
+	// Classify the vertices.
+	//
+	vtype := make([]int, n)
+	for i := range pts {
+		p, v, q := pts[prv[i]], pts[i], pts[nxt[i]]
+		cp, cq := p.CmpXY(v), q.CmpXY(v)
+		convex := p.Orientation(v, q) > 0
+		switch {
+		case cp > 0 && cq > 0 && convex:
+			vtype[i] = vtxStart
+		case cp > 0 && cq > 0:
+			vtype[i] = vtxSplit
+		case cp < 0 && cq < 0 && convex:
+			vtype[i] = vtxEnd
+		case cp < 0 && cq < 0:
+			vtype[i] = vtxMerge
+		default:
+			vtype[i] = vtxRegular
+		}
+	}
+	//
+	// The status holds the edges (e,nxt[e]) having the interior above them, ordered from below to above.
+	// The edge e is identified by its first vertex.
+	//
+	status := make([]int, 0)
+	helper := make([]int, n)
+	diags := make([][2]int, 0)
+	//
+	// below(v) the number of edges in the status strictly below pts[v].
+	//
+	below := func(v int) int {
+		return sort.Search(len(status), func(k int) bool {
+			e := status[k]
+			return pts[e].Orientation(pts[nxt[e]], pts[v]) <= 0
+		})
+	}
+	insert := func(k, e int) {
+		status = append(status, 0)
+		copy(status[k+1:], status[k:])
+		status[k] = e
+	}
+	remove := func(k int) {
+		copy(status[k:], status[k+1:])
+		status = status[:len(status)-1]
+	}
+	diagonal := func(v, e int) {
+		if vtype[helper[e]] == vtxMerge {
+			diags = append(diags, [2]int{v, helper[e]})
+		}
+	}
+	for _, v := range order {
+		switch vtype[v] {
+		case vtxStart:
+			insert(below(v), v)
+			helper[v] = v
+		case vtxEnd:
+			e := prv[v]
+			diagonal(v, e)
+			remove(below(v))
+		case vtxSplit:
+			k := below(v)
+			e := status[k-1]
+			diags = append(diags, [2]int{v, helper[e]})
+			helper[e] = v
+			insert(k, v)
+			helper[v] = v
+		case vtxMerge:
+			e := prv[v]
+			diagonal(v, e)
+			remove(below(v))
+			e = status[below(v)-1]
+			diagonal(v, e)
+			helper[e] = v
+		default:
+			if pts[prv[v]].CmpXY(pts[v]) < 0 {
+				//
+				// The interior is above v: replace the edge ending at v with the edge starting at v.
+				//
+				e := prv[v]
+				diagonal(v, e)
+				status[below(v)] = v
+				helper[v] = v
+			} else {
+				e := status[below(v)-1]
+				diagonal(v, e)
+				helper[e] = v
+			}
+		}
+	}
+	return diags
+}
+
+// faces2q returns the faces of the polygon given by the rings (pts,nxt,prv) subdivided by diags.
+// The vertices of each face are listed in counter-clockwise order.
+func faces2q(pts []Point2q, nxt, prv []int, diags [][2]int) [][]int {
+	n := len(pts)
+	adj := make([][]int, n)
+	for i := 0; i < n; i++ {
+		adj[i] = []int{nxt[i], prv[i]}
+	}
+	type half struct{ u, v int }
+	start := make([]half, 0, n+2*len(diags))
+	for i := 0; i < n; i++ {
+		start = append(start, half{i, nxt[i]})
+	}
+	for _, d := range diags {
+		adj[d[0]] = append(adj[d[0]], d[1])
+		adj[d[1]] = append(adj[d[1]], d[0])
+		start = append(start, half{d[0], d[1]}, half{d[1], d[0]})
+	}
+	//
+	// turn(u,v) the first neighbor of v clockwise from u, i.e., the next edge of the face
+	// on the left of (u,v).
+	//
+	turn := func(u, v int) int {
+		ws := make([]Point2q, len(adj[v]))
+		for k, w := range adj[v] {
+			ws[k] = pts[w]
+		}
+		return adj[v][cwturn2q(pts[v], pts[u], ws)]
+	}
+	done := make(map[half]bool, len(start))
+	res := make([][]int, 0)
+	for _, h := range start {
+		if done[h] {
+			continue
+		}
+		face := make([]int, 0)
+		for !done[h] {
+			done[h] = true
+			face = append(face, h.u)
+			h = half{h.v, turn(h.u, h.v)}
+		}
+		res = append(res, face)
+	}
+	return res
+}
+
+// cwturn2q returns the index of the first point in ws clockwise around c from the direction of u.
+// The direction of u itself comes last.
+func cwturn2q(c, u Point2q, ws []Point2q) int {
+	r := c.Vector(u)
+	group := func(w Point2q) int {
+		d := c.Vector(w)
+		switch det := Det2x2(r.x, r.y, d.x, d.y).Sgn(); {
+		case det < 0:
+			return 0
+		case det > 0:
+			return 2
+		case r.Dot(d).Sgn() < 0:
+			return 1
+		}
+		return 3
+	}
+	best, bg := -1, 0
+	for k, w := range ws {
+		g := group(w)
+		if best < 0 || g < bg || (g == bg && (g == 0 || g == 2) && c.Orientation(w, ws[best]) < 0) {
+			best, bg = k, g
+		}
+	}
+	return best
+}
+
+// earclip2q appends to res the len(face)-2 triangles of the simple counter-clockwise polygon
+// pts[face] computed by ear clipping. It returns false if there is no ear, which by the two ears
+// theorem happens only if the polygon is not simple.
+//
+// Reference: G.H. Meisters, Polygons have ears, Amer. Math. Monthly, 82:648-651 (1975).
+//
+// See: http://dx.doi.org/10.2307/2319703
+func earclip2q(pts []Point2q, face []int, res [][3]int) ([][3]int, bool) {
+	n := len(face)
+	next := make([]int, n)
+	prev := make([]int, n)
+	for i := range face {
+		next[i], prev[i] = (i+1)%n, (i+n-1)%n
+	}
+	//
+	// convex(i) the vertex i is a strictly convex vertex of the current polygon.
+	//
+	convex := func(i int) bool {
+		return pts[face[prev[i]]].Orientation(pts[face[i]], pts[face[next[i]]]) > 0
+	}
+	//
+	// The vertices that are not strictly convex; a strictly convex vertex stays so after clipping.
+	//
+	alive := make([]bool, n)
+	cand := make([]int, 0)
+	for i := range face {
+		alive[i] = true
+		if !convex(i) {
+			cand = append(cand, i)
+		}
+	}
+	//
+	// ear(i) the triangle (prev[i],i,next[i]) contains no other vertex of the current polygon.
+	// Only the vertices that are not strictly convex can be inside.
+	//
+	ear := func(i int) bool {
+		if !convex(i) {
+			return false
+		}
+		a, b, c := pts[face[prev[i]]], pts[face[i]], pts[face[next[i]]]
+		k := 0
+		for m, j := range cand {
+			if !alive[j] || convex(j) {
+				continue
+			}
+			cand[k] = j
+			k++
+			if j == prev[i] || j == next[i] {
+				continue
+			}
+			p := pts[face[j]]
+			if p.CmpXY(a) == 0 || p.CmpXY(c) == 0 {
+				continue
+			}
+			if a.Orientation(b, p) >= 0 && b.Orientation(c, p) >= 0 && c.Orientation(a, p) >= 0 {
+				cand = append(cand[:k], cand[m+1:]...)
+				return false
+			}
+		}
+		cand = cand[:k]
+		return true
+	}
+	i, left, miss := 0, n, 0
+	for left > 3 && miss < left {
+		if !ear(i) {
+			i = next[i]
+			miss++
+			continue
+		}
+		res = append(res, [3]int{face[prev[i]], face[i], face[next[i]]})
+		alive[i] = false
+		next[prev[i]], prev[next[i]] = next[i], prev[i]
+		i = prev[i]
+		left--
+		miss = 0
+	}
+	if left > 3 || !convex(i) {
+		return res, false
+	}
+	res = append(res, [3]int{face[prev[i]], face[i], face[next[i]]})
+	return res, true
+}
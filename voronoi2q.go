@@ -0,0 +1,167 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import "sort"
+
+// VoronoiEdge2q represents an edge of a Voronoi diagram in the plane. The edge lies on the
+// perpendicular bisector of two sites and is directed so that the left site is on its left.
+type VoronoiEdge2q struct {
+	left, right int
+	from, to    int
+	dir         Vector2q
+}
+
+// Sites returns the indices of the sites on the left and on the right of e.
+func (e VoronoiEdge2q) Sites() (left, right int) {
+	return e.left, e.right
+}
+
+// Vertices returns the indices of the first and the last vertices of e.
+// The index -1 denotes a point at infinity: if from=-1 (to=-1), then e is a ray
+// coming from (going to) infinity; if both are -1, then e is the whole bisector.
+func (e VoronoiEdge2q) Vertices() (from, to int) {
+	return e.from, e.to
+}
+
+// Direction returns a direction vector of e. It is the vector from the left site to
+// the right site rotated by 90 degrees counter-clockwise.
+func (e VoronoiEdge2q) Direction() Vector2q {
+	return e.dir
+}
+
+// Voronoi2q represents the Voronoi diagram of a finite set of points in the plane.
+// The vertices of the diagram are the exact circumcenters of the Delaunay triangles.
+type Voronoi2q struct {
+	sites   []Point2q
+	verts   []Point2q
+	edges   []VoronoiEdge2q
+	cells   [][]int
+	bounded []bool
+}
+
+// Sites returns the sites of v. The sites are distinct and listed in (x,y)-order.
+func (v Voronoi2q) Sites() []Point2q {
+	return v.sites
+}
+
+// Vertices returns the vertices of v. The vertices are distinct and listed in (x,y)-order.
+func (v Voronoi2q) Vertices() []Point2q {
+	return v.verts
+}
+
+// Edges returns the edges of v.
+func (v Voronoi2q) Edges() []VoronoiEdge2q {
+	return v.edges
+}
+
+// Cell returns the indices into v.Edges() of the edges bounding the cell of the i-th site.
+// The edges are listed in counter-clockwise order around the site. If the cell is unbounded,
+// then the list starts with an edge coming from infinity and ends with an edge going to infinity.
+func (v Voronoi2q) Cell(i int) []int {
+	return v.cells[i]
+}
+
+// Bounded reports whether the cell of the i-th site is bounded.
+func (v Voronoi2q) Bounded(i int) bool {
+	return v.bounded[i]
+}
+
+// Voronoi computes the Voronoi diagram dual to t. The triangulation t must be
+// the Delaunay triangulation returned by Delaunay2q. The vertices shared by cocircular
+// triangles are merged, and the edges of zero length are not reported.
+func (t Triangulation2q) Voronoi() Voronoi2q {
+	ns := len(t.ps)
+	v := Voronoi2q{t.ps, []Point2q{}, []VoronoiEdge2q{}, make([][]int, ns), make([]bool, ns)}
+	//
+	// bisector(s,r) the edge between the sites s and r.
+	//
+	bisector := func(s, r, from, to int) VoronoiEdge2q {
+		d := t.ps[s].Vector(t.ps[r])
+		return VoronoiEdge2q{s, r, from, to, XYtoV(d.y.Neg(), d.x)}
+	}
+	if len(t.tri) == 0 {
+		//
+		// Collinear sites: the diagram consists of parallel lines.
+		//
+		for s := 0; s+1 < ns; s++ {
+			v.edges = append(v.edges, bisector(s, s+1, -1, -1))
+			v.cells[s] = append(v.cells[s], len(v.edges)-1)
+			v.cells[s+1] = append(v.cells[s+1], len(v.edges)-1)
+		}
+		return v
+	}
+	//
+	// Compute the circumcenters and merge the equal ones.
+	//
+	cs := make([]Point2q, len(t.tri))
+	for k, tr := range t.tri {
+		cs[k] = PPPtoCir(t.ps[tr[0]], t.ps[tr[1]], t.ps[tr[2]]).Center()
+	}
+	order := make([]int, len(cs))
+	for k := range order {
+		order[k] = k
+	}
+	sort.Slice(order, func(i, j int) bool { return cs[order[i]].CmpXY(cs[order[j]]) < 0 })
+	vert := make([]int, len(cs))
+	for i, k := range order {
+		if i == 0 || cs[k].CmpXY(cs[order[i-1]]) != 0 {
+			v.verts = append(v.verts, cs[k])
+		}
+		vert[k] = len(v.verts) - 1
+	}
+	//
+	// Each Delaunay edge (b,c) of the triangle k is dual to an edge going from
+	// the neighbor of k (or infinity) to k.
+	//
+	for k, tr := range t.tri {
+		for i := 0; i < 3; i++ {
+			u := t.adj[k][i]
+			if u > k {
+				continue
+			}
+			from := -1
+			if u >= 0 {
+				from = vert[u]
+			}
+			if from == vert[k] {
+				continue
+			}
+			b, c := tr[(i+1)%3], tr[(i+2)%3]
+			v.edges = append(v.edges, bisector(b, c, from, vert[k]))
+			v.cells[b] = append(v.cells[b], len(v.edges)-1)
+			v.cells[c] = append(v.cells[c], len(v.edges)-1)
+		}
+	}
+	//
+	// Chain the edges of each cell in counter-clockwise order.
+	// The left site traverses an edge forward, the right site traverses it backward.
+	//
+	ends := func(s, e int) (head, tail int) {
+		if v.edges[e].left == s {
+			return v.edges[e].from, v.edges[e].to
+		}
+		return v.edges[e].to, v.edges[e].from
+	}
+	for s := 0; s < ns; s++ {
+		cell := v.cells[s]
+		v.bounded[s] = true
+		for i, e := range cell {
+			if head, _ := ends(s, e); head < 0 {
+				cell[0], cell[i] = cell[i], cell[0]
+				v.bounded[s] = false
+				break
+			}
+		}
+		for i := 1; i < len(cell); i++ {
+			_, tail := ends(s, cell[i-1])
+			for j := i; j < len(cell); j++ {
+				if head, _ := ends(s, cell[j]); head == tail {
+					cell[i], cell[j] = cell[j], cell[i]
+					break
+				}
+			}
+		}
+	}
+	return v
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Well-Known Binary (WKB) representation of geometry, see OpenGIS Implementation Specification
+// for Geographic information - Simple feature access - Part 1: Common architecture, OGC 06-103r4.
+//
+// The supported geometries are Point, MultiPoint, LineString and Polygon with 2-dimensional
+// coordinates. The coordinates are IEEE 754 float64 numbers, so that a WKB value is read exactly.
+// A WKB value is written in little-endian byte order (NDR); if a coordinate is not exactly
+// representable as a float64, then an error is returned: such coordinates are written exactly
+// by the WKT functions. Both byte orders are read, as well as the PostGIS extended WKB (EWKB)
+// with an SRID, which is ignored.
+
+// WKB geometry types.
+const (
+	wkbPoint      = 1
+	wkbLineString = 2
+	wkbPolygon    = 3
+	wkbMultiPoint = 4
+)
+
+// EWKB flags.
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+var errInexactWKB = fmt.Errorf("%w as a float64 in WKB", ErrInexact)
+
+// WKB returns the WKB representation of a.
+func (a Point2q) WKB() ([]byte, error) {
+	w := wkbwriter{}
+	w.header(wkbPoint)
+	w.point(a)
+	return w.b, w.err
+}
+
+// MultiPointtoWKB returns the WKB representation of the multipoint ps.
+func MultiPointtoWKB(ps []Point2q) ([]byte, error) {
+	w := wkbwriter{}
+	w.header(wkbMultiPoint)
+	w.uint32(len(ps))
+	for _, p := range ps {
+		w.header(wkbPoint)
+		w.point(p)
+	}
+	return w.b, w.err
+}
+
+// LineStringtoWKB returns the WKB representation of the polyline ps.
+func LineStringtoWKB(ps []Point2q) ([]byte, error) {
+	w := wkbwriter{}
+	w.header(wkbLineString)
+	w.ring(ps, false)
+	return w.b, w.err
+}
+
+// WKB returns the WKB representation of p. The ring is closed, i.e., its first point
+// is repeated at the end.
+func (p Polygon2q) WKB() ([]byte, error) {
+	return PolygontoWKB(p)
+}
+
+// PolygontoWKB returns the WKB representation of the polygon outer with the given holes.
+// The rings are closed, the outer ring is counter-clockwise, and the holes are clockwise.
+func PolygontoWKB(outer Polygon2q, holes ...Polygon2q) ([]byte, error) {
+	w := wkbwriter{}
+	w.header(wkbPolygon)
+	if len(outer.vs) == 0 {
+		w.uint32(0)
+		return w.b, w.err
+	}
+	rings := PolytoReg(outer, holes...).rings
+	w.uint32(len(rings))
+	for _, p := range rings {
+		w.ring(p.vs, true)
+	}
+	return w.b, w.err
+}
+
+// wkbwriter appends little-endian WKB to b; err is the first error.
+type wkbwriter struct {
+	b   []byte
+	err error
+}
+
+func (w *wkbwriter) header(typ int) {
+	w.b = append(w.b, 1)
+	w.uint32(typ)
+}
+
+func (w *wkbwriter) uint32(n int) {
+	w.b = binary.LittleEndian.AppendUint32(w.b, uint32(n))
+}
+
+func (w *wkbwriter) point(a Point2q) {
+	for _, x := range [2]Q{a.x, a.y} {
+		f, exact := r(x).Float64()
+		if !exact && w.err == nil {
+			w.err = errInexactWKB
+		}
+		w.b = binary.LittleEndian.AppendUint64(w.b, math.Float64bits(f))
+	}
+}
+
+// ring appends the number of points and the points; if closed, then the first point
+// is repeated at the end.
+func (w *wkbwriter) ring(ps []Point2q, closed bool) {
+	if closed && len(ps) > 0 {
+		ps = append(ps[:len(ps):len(ps)], ps[0])
+	}
+	w.uint32(len(ps))
+	for _, p := range ps {
+		w.point(p)
+	}
+}
+
+// WKBtoP returns the point represented by the WKB value b.
+func WKBtoP(b []byte) (Point2q, error) {
+	r := &wkbreader{b: b}
+	r.header(wkbPoint)
+	a := r.point()
+	return a, r.end()
+}
+
+// WKBtoPs returns the points represented by the WKB value b, a MultiPoint or a LineString.
+func WKBtoPs(b []byte) ([]Point2q, error) {
+	r := &wkbreader{b: b}
+	var ps []Point2q
+	if typ := r.header(0); typ == wkbLineString {
+		ps = r.ring()
+	} else if r.check(typ == wkbMultiPoint, "expected MultiPoint or LineString") {
+		n := r.count(21)
+		ps = make([]Point2q, 0, n)
+		for i := 0; i < n && r.err == nil; i++ {
+			r.header(wkbPoint)
+			ps = append(ps, r.point())
+		}
+	}
+	if err := r.end(); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+// WKBtoPoly returns the polygon with holes represented by the WKB value b.
+// The rings must be closed; the repeated last point is removed.
+func WKBtoPoly(b []byte) (outer Polygon2q, holes []Polygon2q, err error) {
+	r := &wkbreader{b: b}
+	r.header(wkbPolygon)
+	n := r.count(4)
+	holes = make([]Polygon2q, 0)
+	for i := 0; i < n && r.err == nil; i++ {
+		vs := r.ring()
+		m := len(vs)
+		if !r.check(m > 0 && vs[0].CmpXY(vs[m-1]) == 0, "polygon ring is not closed") {
+			break
+		}
+		p := Polygon2q{vs[:m-1]}
+		if i == 0 {
+			outer = p
+		} else {
+			holes = append(holes, p)
+		}
+	}
+	if err := r.end(); err != nil {
+		return Polygon2q{}, nil, err
+	}
+	return outer, holes, nil
+}
+
+// wkbreader reads WKB from b; err is the first error, after which the reads return zero values.
+type wkbreader struct {
+	b     []byte
+	i     int
+	order binary.ByteOrder
+	err   error
+}
+
+// check sets the error msg unless ok; it reports whether there is no error.
+func (r *wkbreader) check(ok bool, msg string) bool {
+	if !ok && r.err == nil {
+		r.err = errors.New("pq: WKB: " + msg)
+	}
+	return r.err == nil
+}
+
+// end returns the error, if any, or an error if there are unread bytes.
+func (r *wkbreader) end() error {
+	r.check(r.i == len(r.b), "unexpected data after geometry")
+	return r.err
+}
+
+func (r *wkbreader) bytes(n int) []byte {
+	if !r.check(len(r.b)-r.i >= n, "unexpected end of data") {
+		return make([]byte, n)
+	}
+	r.i += n
+	return r.b[r.i-n : r.i]
+}
+
+func (r *wkbreader) uint32() uint32 {
+	b := r.bytes(4)
+	if r.order == nil {
+		return 0
+	}
+	return r.order.Uint32(b)
+}
+
+// header reads the byte order and the geometry type; if typ is not zero, then
+// the geometry type must be typ. It returns the geometry type.
+func (r *wkbreader) header(typ uint32) uint32 {
+	switch r.bytes(1)[0] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		r.check(false, "invalid byte order")
+	}
+	t := r.uint32()
+	//
+	// EWKB: skip the SRID; reject Z and M coordinates, also in the ISO form 1000+t, 2000+t, 3000+t.
+	//
+	if t&ewkbSRID != 0 {
+		r.uint32()
+		t &^= ewkbSRID
+	}
+	r.check(t&(ewkbZ|ewkbM) == 0 && t < 1000, "only 2-dimensional coordinates are supported")
+	r.check(typ == 0 || t == typ, "unexpected geometry type")
+	return t
+}
+
+// count reads a number of items of at least size bytes each.
+func (r *wkbreader) count(size int) int {
+	n := r.uint32()
+	if !r.check(uint64(n)*uint64(size) <= uint64(len(r.b)-r.i), "unexpected end of data") {
+		return 0
+	}
+	return int(n)
+}
+
+func (r *wkbreader) point() Point2q {
+	var xy [2]Q
+	for k := range xy {
+		b := r.bytes(8)
+		if r.order == nil {
+			return Point2q{}
+		}
+		q, err := FtoQErr(math.Float64frombits(r.order.Uint64(b)))
+		if err != nil {
+			if r.err == nil {
+				r.err = err
+			}
+			return Point2q{}
+		}
+		xy[k] = q
+	}
+	return Point2q{xy[0], xy[1]}
+}
+
+func (r *wkbreader) ring() []Point2q {
+	n := r.count(16)
+	ps := make([]Point2q, 0, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		ps = append(ps, r.point())
+	}
+	return ps
+}
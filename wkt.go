@@ -0,0 +1,299 @@
+// Copyright (c) 2015 Leonid Kneller
+
+package pq
+
+import (
+	"errors"
+	"strings"
+)
+
+// Well-Known Text (WKT) representation of geometry, see OpenGIS Implementation Specification
+// for Geographic information - Simple feature access - Part 1: Common architecture, OGC 06-103r4.
+//
+// The supported geometries are POINT, MULTIPOINT, LINESTRING and POLYGON with 2-dimensional
+// coordinates. A coordinate is written as a decimal number if its decimal expansion terminates
+// (the denominator is 2^a·5^b), e.g. 12.375 or -0.1, and in the rational extension "n/d" otherwise,
+// e.g. 1/3. A coordinate is read in any form accepted by ParseQ, so that a WKT text written here
+// is read back exactly. An optional PostGIS prefix "SRID=n;" is ignored.
+
+// WKT returns the WKT representation of a, "POINT (x y)".
+func (a Point2q) WKT() string {
+	return "POINT " + wktring([]Point2q{a}, false)
+}
+
+// MultiPointtoWKT returns the WKT representation of ps, "MULTIPOINT ((x y), ...)".
+func MultiPointtoWKT(ps []Point2q) string {
+	if len(ps) == 0 {
+		return "MULTIPOINT EMPTY"
+	}
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = wktring([]Point2q{p}, false)
+	}
+	return "MULTIPOINT (" + strings.Join(s, ", ") + ")"
+}
+
+// LineStringtoWKT returns the WKT representation of the polyline ps, "LINESTRING (x y, ...)".
+func LineStringtoWKT(ps []Point2q) string {
+	if len(ps) == 0 {
+		return "LINESTRING EMPTY"
+	}
+	return "LINESTRING " + wktring(ps, false)
+}
+
+// WKT returns the WKT representation of p, "POLYGON ((x y, ...))". The ring is closed,
+// i.e., its first point is repeated at the end.
+func (p Polygon2q) WKT() string {
+	return PolygontoWKT(p)
+}
+
+// PolygontoWKT returns the WKT representation of the polygon outer with the given holes,
+// "POLYGON ((x y, ...), ...)". The rings are closed, the outer ring is counter-clockwise,
+// and the holes are clockwise.
+func PolygontoWKT(outer Polygon2q, holes ...Polygon2q) string {
+	if len(outer.vs) == 0 {
+		return "POLYGON EMPTY"
+	}
+	s := make([]string, 0, 1+len(holes))
+	for _, p := range PolytoReg(outer, holes...).rings {
+		s = append(s, wktring(p.vs, true))
+	}
+	return "POLYGON (" + strings.Join(s, ", ") + ")"
+}
+
+// CirtoWKT returns the WKT representation of the circle c through the point a as the curve polygon
+// "CURVEPOLYGON (CIRCULARSTRING (a, a', a))", where a' is the point of c opposite to a (SQL/MM).
+// A circle computed by MinCircle2q passes through each of the support points given by MinCircle2.
+// If a is not on c, a run-time panic occurs.
+func CirtoWKT(c Circle2q, a Point2q) string {
+	return must(CirtoWKTErr(c, a))
+}
+
+// CirtoWKTErr is like CirtoWKT, but it returns ErrNotOnCircle if a is not on c.
+func CirtoWKTErr(c Circle2q, a Point2q) (string, error) {
+	if c.Side(a) != 0 {
+		return "", ErrNotOnCircle
+	}
+	b := Point2q{qtwo.Mul(c.cen.x).Sub(a.x), qtwo.Mul(c.cen.y).Sub(a.y)}
+	return "CURVEPOLYGON (CIRCULARSTRING " + wktring([]Point2q{a, b, a}, false) + ")", nil
+}
+
+// wktring returns "(x y, ...)"; if closed, then the first point is repeated at the end.
+func wktring(ps []Point2q, closed bool) string {
+	if closed && len(ps) > 0 {
+		ps = append(ps[:len(ps):len(ps)], ps[0])
+	}
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = FormatQ(p.x) + " " + FormatQ(p.y)
+	}
+	return "(" + strings.Join(s, ", ") + ")"
+}
+
+// WKTtoP returns the point represented by the WKT text "POINT (x y)".
+func WKTtoP(s string) (Point2q, error) {
+	g, err := parsewkt(s, "POINT")
+	if err != nil {
+		return Point2q{}, err
+	}
+	if g == nil {
+		return Point2q{}, errors.New("pq: WKT: empty point")
+	}
+	return g.point()
+}
+
+// WKTtoPs returns the points represented by the WKT text "MULTIPOINT (...)" or "LINESTRING (...)".
+func WKTtoPs(s string) ([]Point2q, error) {
+	tag, _ := wkttag(s)
+	if tag != "LINESTRING" {
+		tag = "MULTIPOINT"
+	}
+	g, err := parsewkt(s, tag)
+	if err != nil || g == nil {
+		return []Point2q{}, err
+	}
+	ps := make([]Point2q, len(g.sub))
+	for i, e := range g.sub {
+		//
+		// The points of a MULTIPOINT may be parenthesized.
+		//
+		if tag == "MULTIPOINT" && e.coords == nil {
+			if ps[i], err = e.point(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if ps[i], err = e.xy(); err != nil {
+			return nil, err
+		}
+	}
+	return ps, nil
+}
+
+// WKTtoPoly returns the polygon with holes represented by the WKT text "POLYGON ((...), ...)".
+// The rings must be closed; the repeated last point is removed.
+func WKTtoPoly(s string) (outer Polygon2q, holes []Polygon2q, err error) {
+	g, err := parsewkt(s, "POLYGON")
+	if err != nil || g == nil {
+		return Polygon2q{}, nil, err
+	}
+	holes = make([]Polygon2q, 0, len(g.sub)-1)
+	for i, e := range g.sub {
+		if e.coords != nil || len(e.sub) == 0 {
+			return Polygon2q{}, nil, errors.New("pq: WKT: invalid polygon ring")
+		}
+		vs := make([]Point2q, len(e.sub))
+		for j, c := range e.sub {
+			if vs[j], err = c.xy(); err != nil {
+				return Polygon2q{}, nil, err
+			}
+		}
+		n := len(vs)
+		if vs[0].CmpXY(vs[n-1]) != 0 {
+			return Polygon2q{}, nil, errors.New("pq: WKT: polygon ring is not closed")
+		}
+		p := Polygon2q{vs[:n-1]}
+		if i == 0 {
+			outer = p
+		} else {
+			holes = append(holes, p)
+		}
+	}
+	return outer, holes, nil
+}
+
+// wktelem is an element of a WKT text: either a sequence of coordinates,
+// or a parenthesized list of elements.
+type wktelem struct {
+	coords []Q
+	sub    []wktelem
+}
+
+// xy returns the point with the coordinates e.coords.
+func (e wktelem) xy() (Point2q, error) {
+	if len(e.coords) != 2 {
+		return Point2q{}, errors.New("pq: WKT: expected 2 coordinates")
+	}
+	return Point2q{e.coords[0], e.coords[1]}, nil
+}
+
+// point returns the point given by the list "(x y)".
+func (e wktelem) point() (Point2q, error) {
+	if len(e.sub) != 1 {
+		return Point2q{}, errors.New("pq: WKT: expected a single point")
+	}
+	return e.sub[0].xy()
+}
+
+// wkttag returns the geometry type of the WKT text s in upper case and the byte offset
+// of the end of the type in s. The type is scanned in ASCII, so that the offset is valid
+// for any s.
+func wkttag(s string) (string, int) {
+	i := 0
+	skip := func() {
+		for i < len(s) && strings.IndexByte(" \t\r\n", s[i]) >= 0 {
+			i++
+		}
+	}
+	skip()
+	if j := strings.IndexByte(s[i:], ';'); j >= 0 && len(s)-i >= 5 && strings.EqualFold(s[i:i+5], "SRID=") {
+		i += j + 1
+		skip()
+	}
+	k := i
+	for i < len(s) && ('A' <= s[i] && s[i] <= 'Z' || 'a' <= s[i] && s[i] <= 'z') {
+		i++
+	}
+	return strings.ToUpper(s[k:i]), i
+}
+
+// parsewkt parses the WKT text s of the given geometry type. It returns nil for "tag EMPTY".
+func parsewkt(s, tag string) (*wktelem, error) {
+	t, i := wkttag(s)
+	if t != tag {
+		return nil, errors.New("pq: WKT: expected " + tag + ", got " + t)
+	}
+	rest := strings.TrimSpace(s[i:])
+	if strings.EqualFold(rest, "EMPTY") {
+		return nil, nil
+	}
+	if !strings.HasPrefix(rest, "(") {
+		return nil, errors.New("pq: WKT: unsupported or invalid " + tag + " (only 2-dimensional coordinates are supported)")
+	}
+	p := &wktparser{s: rest}
+	e, err := p.list()
+	if err != nil {
+		return nil, err
+	}
+	if p.skip(); p.i != len(p.s) {
+		return nil, errors.New("pq: WKT: unexpected text after " + tag)
+	}
+	return &e, nil
+}
+
+// wktparser is a recursive descent parser of the parenthesized lists.
+type wktparser struct {
+	s string
+	i int
+}
+
+func (p *wktparser) skip() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// list parses "(" elem {"," elem} ")", where elem is a list or a sequence of numbers.
+func (p *wktparser) list() (wktelem, error) {
+	p.skip()
+	if p.i >= len(p.s) || p.s[p.i] != '(' {
+		return wktelem{}, errors.New("pq: WKT: expected '('")
+	}
+	p.i++
+	res := wktelem{sub: make([]wktelem, 0)}
+	for {
+		p.skip()
+		var e wktelem
+		if p.i < len(p.s) && p.s[p.i] == '(' {
+			sub, err := p.list()
+			if err != nil {
+				return wktelem{}, err
+			}
+			e = sub
+		} else {
+			for {
+				p.skip()
+				j := p.i
+				for j < len(p.s) && strings.IndexByte(" \t\r\n(),", p.s[j]) < 0 {
+					j++
+				}
+				if j == p.i {
+					break
+				}
+				q, err := ParseQ(p.s[p.i:j])
+				if err != nil {
+					return wktelem{}, err
+				}
+				e.coords = append(e.coords, q)
+				p.i = j
+			}
+			if e.coords == nil {
+				return wktelem{}, errors.New("pq: WKT: expected coordinates")
+			}
+		}
+		res.sub = append(res.sub, e)
+		p.skip()
+		if p.i >= len(p.s) {
+			return wktelem{}, errors.New("pq: WKT: expected ')'")
+		}
+		switch p.s[p.i] {
+		case ',':
+			p.i++
+		case ')':
+			p.i++
+			return res, nil
+		default:
+			return wktelem{}, errors.New("pq: WKT: expected ',' or ')'")
+		}
+	}
+}